@@ -0,0 +1,125 @@
+package botfilter
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Traffic classes
+const (
+	Human = "human"
+	Bot   = "bot"
+	// Preview is a link preview fetcher, such as a chat app unfurling a shared link
+	Preview = "preview"
+)
+
+//go:embed signatures.txt
+var defaultSignatures string
+
+// Signature classifies the User-Agents matching Pattern
+type Signature struct {
+	Class   string
+	Pattern *regexp.Regexp
+}
+
+// ParseSignatures reads signatures, one per line: a class (bot or preview)
+// followed by a regular expression, matched ignoring case. Blank lines and
+// lines starting with # are ignored.
+func ParseSignatures(r io.Reader) ([]Signature, error) {
+	var signatures []Signature
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		class, expr, _ := strings.Cut(line, " ")
+		expr = strings.TrimSpace(expr)
+		if class != Bot && class != Preview {
+			return nil, fmt.Errorf("line %d: unknown class %q", n, class)
+		}
+		if expr == "" {
+			return nil, fmt.Errorf("line %d: missing pattern", n)
+		}
+		pattern, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		signatures = append(signatures, Signature{Class: class, Pattern: pattern})
+	}
+	return signatures, scanner.Err()
+}
+
+// Classifier tells humans from bots and link preview fetchers
+type Classifier struct {
+	Signatures []Signature
+}
+
+// NewClassifier returns a Classifier using the built-in signatures
+func NewClassifier() *Classifier {
+	signatures, err := ParseSignatures(strings.NewReader(defaultSignatures))
+	if err != nil {
+		panic("botfilter: invalid built-in signatures: " + err.Error())
+	}
+	return &Classifier{Signatures: signatures}
+}
+
+// LoadSignatures replaces the signatures with those in the file at path
+func (c *Classifier) LoadSignatures(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	signatures, err := ParseSignatures(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	c.Signatures = signatures
+	return nil
+}
+
+// Classify returns the traffic class of a request: the class of the first
+// signature matching its User-Agent or, failing that, what its method and
+// headers suggest
+func (c *Classifier) Classify(r *http.Request) string {
+	userAgent := r.UserAgent()
+	for _, signature := range c.Signatures {
+		if signature.Pattern.MatchString(userAgent) {
+			return signature.Class
+		}
+	}
+
+	switch {
+	// Browsers prefetching or prerendering a page the user may never open
+	case isPrefetch(r.Header):
+		return Preview
+	// People follow links with GET; HEAD comes from monitors and unfurlers
+	// checking what's behind them. Proxies resolving links on behalf of
+	// visitors pass the visitor's method on in X-Forwarded-Method.
+	case r.Method == http.MethodHead || r.Header.Get("X-Forwarded-Method") == http.MethodHead:
+		return Bot
+	// Every browser sends a User-Agent
+	case strings.TrimSpace(userAgent) == "":
+		return Bot
+	}
+	return Human
+}
+
+func isPrefetch(header http.Header) bool {
+	for _, name := range []string{"Purpose", "Sec-Purpose", "X-Purpose", "X-Moz"} {
+		value := strings.ToLower(header.Get(name))
+		if strings.Contains(value, "prefetch") || strings.Contains(value, "prerender") || strings.Contains(value, "preview") {
+			return true
+		}
+	}
+	return false
+}
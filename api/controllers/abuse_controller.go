@@ -0,0 +1,583 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// abuseReportCursor is the position encoded in abuse report cursors
+type abuseReportCursor struct {
+	ID uint `json:"id"`
+}
+
+// abuseFlagThreshold is how many of a user's links must be disabled by admins
+// before the account is flagged
+func abuseFlagThreshold() int64 {
+	return int64(initializers.GetEnvInt("ABUSE_FLAG_THRESHOLD", 3))
+}
+
+// rejectFlaggedUser writes an error response and returns true if user may not create
+// links or point them anywhere new
+func rejectFlaggedUser(c *gin.Context, user models.User) bool {
+	if user.FlaggedAt == nil {
+		return false
+	}
+	c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+		Success: false,
+		Error:   "Your account has been flagged for abuse and can't create links or change their destinations",
+	})
+	return true
+}
+
+func toAbuseReportResponse(report models.AbuseReport) dtos.AbuseReportResponse {
+	return dtos.AbuseReportResponse{
+		ID:             report.ID,
+		Reason:         report.Reason,
+		Details:        report.Details,
+		ReporterEmail:  report.ReporterEmail,
+		Status:         report.Status,
+		Link:           toLinkResponse(report.Link),
+		ReviewedByID:   report.ReviewedByID,
+		ReviewedAt:     report.ReviewedAt,
+		ResolutionNote: report.ResolutionNote,
+		CreatedAt:      report.CreatedAt,
+	}
+}
+
+// findLinkByParam loads any link, trashed ones included, by the :id URL param,
+// writing the error response if there is none
+func findLinkByParam(c *gin.Context) (models.Link, bool) {
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return models.Link{}, false
+	}
+
+	var link models.Link
+	if err := initializers.DB.Unscoped().Preload("Tags").First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return models.Link{}, false
+	}
+	return link, true
+}
+
+// disableLink disables link on behalf of admin, then flags the owner if they keep
+// getting links disabled
+func disableLink(c *gin.Context, tx *gorm.DB, admin models.User, link *models.Link, reason string) error {
+	now := time.Now()
+	if err := tx.Model(link).Updates(map[string]interface{}{
+		"disabled_at":     now,
+		"disabled_reason": reason,
+		"disabled_by":     models.LinkDisabledByAdmin,
+	}).Error; err != nil {
+		return err
+	}
+	link.DisabledAt = &now
+	link.DisabledReason = reason
+	link.DisabledBy = models.LinkDisabledByAdmin
+
+	if err := recordAuditEvent(tx, c, auditEntry{
+		Action:     models.AuditActionLinkDisable,
+		ActorID:    uintPtr(admin.ID),
+		TargetType: models.AuditTargetLink,
+		TargetID:   uintPtr(link.ID),
+		Metadata:   map[string]interface{}{"reason": reason},
+	}); err != nil {
+		return err
+	}
+
+	return flagRepeatOffender(c, tx, link.UserID)
+}
+
+// abuseStrikes counts the admin-disabled links, given by when they were disabled,
+// that count towards flagging their owner: those disabled since an admin last
+// cleared the owner's flag
+func abuseStrikes(disabledAt []time.Time, flagClearedAt *time.Time) int64 {
+	var strikes int64
+	for _, at := range disabledAt {
+		if flagClearedAt == nil || at.After(*flagClearedAt) {
+			strikes++
+		}
+	}
+	return strikes
+}
+
+// flagRepeatOffender flags userID once enough of their links have been disabled by admins
+func flagRepeatOffender(c *gin.Context, tx *gorm.DB, userID uint) error {
+	var owner models.User
+	if err := tx.Select("id", "flag_cleared_at").First(&owner, userID).Error; err != nil {
+		return err
+	}
+	var disabledAt []time.Time
+	if err := tx.Unscoped().Model(&models.Link{}).
+		Where("user_id = ? AND disabled_by = ? AND disabled_at IS NOT NULL", userID, models.LinkDisabledByAdmin).
+		Pluck("disabled_at", &disabledAt).Error; err != nil {
+		return err
+	}
+	disabledCount := abuseStrikes(disabledAt, owner.FlagClearedAt)
+	if disabledCount < abuseFlagThreshold() {
+		return nil
+	}
+
+	reason := strconv.FormatInt(disabledCount, 10) + " links disabled for abuse"
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND flagged_at IS NULL", userID).
+		Updates(map[string]interface{}{"flagged_at": time.Now(), "flag_reason": reason})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		if err := recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionUserFlag,
+			TargetType: models.AuditTargetUser,
+			TargetID:   uintPtr(userID),
+			Metadata:   map[string]interface{}{"reason": reason, "disabledLinks": disabledCount},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReportLink lets anyone report a short link as abusive (no auth required)
+func ReportLink(c *gin.Context) {
+	var req dtos.ReportLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	link, err := findRedirectLink(c.Request.Host, c.Param("shortCode"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Link not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to look up link",
+			})
+		}
+		return
+	}
+
+	// One pending report per link and address is enough
+	var pending int64
+	if err := initializers.DB.Model(&models.AbuseReport{}).
+		Where("link_id = ? AND reporter_ip = ? AND status = ?", link.ID, c.ClientIP(), models.AbuseReportPending).
+		Count(&pending).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to save report",
+		})
+		return
+	}
+
+	if pending == 0 {
+		report := models.AbuseReport{
+			LinkID:        link.ID,
+			Reason:        req.Reason,
+			Details:       req.Details,
+			ReporterEmail: req.Email,
+			ReporterIP:    c.ClientIP(),
+			Status:        models.AbuseReportPending,
+		}
+		if err := initializers.DB.Create(&report).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to save report",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusAccepted, dtos.SuccessResponse{
+		Success: true,
+		Message: "Thanks, the link will be reviewed",
+	})
+}
+
+func GetAbuseReports(c *gin.Context) {
+	var query dtos.AbuseReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// The queue defaults to reports still waiting for review
+	status := query.Status
+	if status == "" {
+		status = models.AbuseReportPending
+	}
+	db := initializers.DB.Preload("Link", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Link.Tags").Where("status = ?", status)
+	if query.LinkID != nil {
+		db = db.Where("link_id = ?", *query.LinkID)
+	}
+
+	// Resume after the cursor position (oldest first, so the queue is worked in order)
+	if query.Cursor != "" {
+		var cursor abuseReportCursor
+		if err := decodeCursor(query.Cursor, &cursor); err != nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Invalid cursor",
+			})
+			return
+		}
+		db = db.Where("id > ?", cursor.ID)
+	}
+
+	// Fetch one extra row to know whether there is another page
+	limit := pageLimit(query.Limit)
+	var reports []models.AbuseReport
+	if err := db.Order("id").Limit(limit + 1).Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch abuse reports",
+		})
+		return
+	}
+
+	paging := dtos.PageInfo{Limit: limit}
+	if len(reports) > limit {
+		reports = reports[:limit]
+		paging.HasMore = true
+		paging.NextCursor = encodeCursor(abuseReportCursor{ID: reports[len(reports)-1].ID})
+	}
+
+	reportResponses := make([]dtos.AbuseReportResponse, 0, len(reports))
+	for _, report := range reports {
+		reportResponses = append(reportResponses, toAbuseReportResponse(report))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    reportResponses,
+		Paging:  &paging,
+	})
+}
+
+func ResolveAbuseReport(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	admin, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	reportID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid report ID",
+		})
+		return
+	}
+
+	var req dtos.ResolveAbuseReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	var report models.AbuseReport
+	if err := initializers.DB.Preload("Link", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Link.Tags").First(&report, reportID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Report not found",
+		})
+		return
+	}
+
+	if report.Status != models.AbuseReportPending {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Report has already been resolved",
+		})
+		return
+	}
+
+	status := models.AbuseReportDismissed
+	if req.Action == "disable" {
+		status = models.AbuseReportActioned
+	}
+
+	now := time.Now()
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if status == models.AbuseReportActioned && report.Link.DisabledAt == nil {
+			reason := req.Note
+			if reason == "" {
+				reason = "Reported for " + report.Reason
+			}
+			if err := disableLink(c, tx, admin, &report.Link, reason); err != nil {
+				return err
+			}
+		}
+
+		// Every pending report of the link shares the outcome
+		return tx.Model(&models.AbuseReport{}).
+			Where("link_id = ? AND status = ?", report.LinkID, models.AbuseReportPending).
+			Updates(map[string]interface{}{
+				"status":          status,
+				"reviewed_by_id":  admin.ID,
+				"reviewed_at":     now,
+				"resolution_note": req.Note,
+			}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to resolve report",
+		})
+		return
+	}
+
+	report.Status = status
+	report.ReviewedByID = uintPtr(admin.ID)
+	report.ReviewedAt = &now
+	report.ResolutionNote = req.Note
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toAbuseReportResponse(report),
+		Message: "Report resolved",
+	})
+}
+
+func DisableLink(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	admin, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var req dtos.DisableLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	link, found := findLinkByParam(c)
+	if !found {
+		return
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		return disableLink(c, tx, admin, &link, req.Reason)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to disable link",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(link),
+		Message: "Link disabled",
+	})
+}
+
+func EnableLink(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	admin, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	link, found := findLinkByParam(c)
+	if !found {
+		return
+	}
+
+	if link.DisabledAt == nil {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link is not disabled",
+		})
+		return
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&link).Updates(map[string]interface{}{
+			"disabled_at":     nil,
+			"disabled_reason": "",
+			"disabled_by":     "",
+		}).Error; err != nil {
+			return err
+		}
+		return recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkEnable,
+			ActorID:    uintPtr(admin.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			Metadata:   map[string]interface{}{"previousReason": link.DisabledReason},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to enable link",
+		})
+		return
+	}
+	link.DisabledAt = nil
+	link.DisabledReason = ""
+	link.DisabledBy = ""
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(link),
+		Message: "Link enabled",
+	})
+}
+
+func UnflagUser(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	admin, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+		return
+	}
+
+	var flagged models.User
+	if err := initializers.DB.First(&flagged, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return
+	}
+
+	if flagged.FlaggedAt == nil {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "User is not flagged",
+		})
+		return
+	}
+
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Links disabled so far no longer count towards flagging the user again
+		if err := tx.Model(&flagged).Updates(map[string]interface{}{
+			"flagged_at":      nil,
+			"flag_reason":     "",
+			"flag_cleared_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionUserUnflag,
+			ActorID:    uintPtr(admin.ID),
+			TargetType: models.AuditTargetUser,
+			TargetID:   uintPtr(flagged.ID),
+			Metadata:   map[string]interface{}{"previousReason": flagged.FlagReason},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to unflag user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Message: "User unflagged",
+	})
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAbuseStrikesRestartAfterUnflag(t *testing.T) {
+	const threshold = 3
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	var disabledAt []time.Time
+	disable := func(day int) {
+		disabledAt = append(disabledAt, start.AddDate(0, 0, day))
+	}
+
+	for day := 0; day < threshold; day++ {
+		disable(day)
+	}
+	if strikes := abuseStrikes(disabledAt, nil); strikes != threshold {
+		t.Fatalf("abuseStrikes() = %d after %d disabled links, want %d", strikes, threshold, threshold)
+	}
+
+	// An admin clears the flag, then one more link is disabled
+	cleared := start.AddDate(0, 0, 10)
+	disable(11)
+	if strikes := abuseStrikes(disabledAt, &cleared); strikes != 1 {
+		t.Errorf("abuseStrikes() = %d after one link disabled since the unflag, want 1", strikes)
+	}
+
+	// Reaching the threshold again flags the user again
+	disable(12)
+	disable(13)
+	if strikes := abuseStrikes(disabledAt, &cleared); strikes != threshold {
+		t.Errorf("abuseStrikes() = %d after %d links disabled since the unflag, want %d", strikes, threshold, threshold)
+	}
+}
@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	analyticsDefaultDays  = 30
+	analyticsMaxDays      = 366
+	analyticsDefaultLimit = 10
+	analyticsDateLayout   = "2006-01-02"
+)
+
+// userRollups selects the user's daily rollups of one dimension in [from, to)
+func userRollups(userID uint, dimension string, from, to time.Time) *gorm.DB {
+	return initializers.DB.Model(&models.ClickRollup{}).
+		Where("user_id = ? AND granularity = ? AND dimension = ? AND bucket_start >= ? AND bucket_start < ?",
+			userID, models.RollupDay, dimension, from, to)
+}
+
+// percentChange is the change from previous to current in percent, nil without a base
+func percentChange(current, previous int64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := float64(current-previous) / float64(previous) * 100
+	return &change
+}
+
+// analyticsTopValues returns the values of a dimension with the most clicks
+func analyticsTopValues(userID uint, dimension string, from, to time.Time, limit int) ([]dtos.AnalyticsValueCount, error) {
+	counts := []dtos.AnalyticsValueCount{}
+	err := userRollups(userID, dimension, from, to).
+		Select("value, SUM(clicks) AS clicks").
+		Where("value <> ''").
+		Group("value").
+		Order("clicks DESC, value").
+		Limit(limit).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// analyticsTopLinks returns the links with the most clicks, trashed ones included
+func analyticsTopLinks(userID uint, from, to time.Time, limit int) ([]dtos.AnalyticsLinkCount, error) {
+	counts := []dtos.AnalyticsLinkCount{}
+	if err := userRollups(userID, models.RollupDimensionTotal, from, to).
+		Select("link_id, SUM(clicks) AS clicks, SUM(visitors) AS visits").
+		Group("link_id").
+		Order("clicks DESC, link_id").
+		Limit(limit).
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return counts, nil
+	}
+
+	ids := make([]uint, len(counts))
+	for i, count := range counts {
+		ids[i] = count.LinkID
+	}
+	var links []models.Link
+	if err := initializers.DB.Unscoped().Where("id IN ?", ids).Find(&links).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Link, len(links))
+	for _, link := range links {
+		byID[link.ID] = link
+	}
+	for i := range counts {
+		link := byID[counts[i].LinkID]
+		counts[i].ShortCode = link.ShortCode
+		counts[i].OriginalURL = link.OriginalURL
+	}
+	return counts, nil
+}
+
+// userVisitors selects the user's visitors of the days in [from, to)
+func userVisitors(userID uint, from, to time.Time) *gorm.DB {
+	return initializers.DB.Model(&models.DailyVisitor{}).
+		Where("user_id = ? AND day >= ? AND day < ?", userID, from, to)
+}
+
+// analyticsDaily returns the clicks of every day in [from, to), including days without any
+func analyticsDaily(userID uint, from, to time.Time) ([]dtos.AnalyticsDayCount, error) {
+	var rows []struct {
+		BucketStart time.Time
+		Clicks      int64
+		Visits      int64
+	}
+	if err := userRollups(userID, models.RollupDimensionTotal, from, to).
+		Select("bucket_start, SUM(clicks) AS clicks, SUM(visitors) AS visits").
+		Group("bucket_start").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	var visitors []struct {
+		Day      time.Time
+		Visitors int64
+	}
+	if err := userVisitors(userID, from, to).
+		Select("day, COUNT(*) AS visitors").
+		Group("day").
+		Scan(&visitors).Error; err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[string]dtos.AnalyticsDayCount, len(rows))
+	for _, row := range rows {
+		date := row.BucketStart.UTC().Format(analyticsDateLayout)
+		byDay[date] = dtos.AnalyticsDayCount{Date: date, Clicks: row.Clicks, Visits: row.Visits}
+	}
+	for _, row := range visitors {
+		date := row.Day.UTC().Format(analyticsDateLayout)
+		count := byDay[date]
+		count.Date = date
+		count.Visitors = row.Visitors
+		byDay[date] = count
+	}
+
+	days := []dtos.AnalyticsDayCount{}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(analyticsDateLayout)
+		count, ok := byDay[date]
+		if !ok {
+			count = dtos.AnalyticsDayCount{Date: date}
+		}
+		days = append(days, count)
+	}
+	return days, nil
+}
+
+// analyticsTotals sums the clicks and visits in [from, to) and counts its unique visitors
+func analyticsTotals(userID uint, from, to time.Time) (dtos.AnalyticsTotals, error) {
+	var totals dtos.AnalyticsTotals
+	err := userRollups(userID, models.RollupDimensionTotal, from, to).
+		Select("COALESCE(SUM(clicks), 0) AS clicks, COALESCE(SUM(visitors), 0) AS visits").
+		Scan(&totals).Error
+	if err != nil {
+		return totals, err
+	}
+	err = userVisitors(userID, from, to).
+		Select("COUNT(DISTINCT visitor_id)").
+		Scan(&totals.Visitors).Error
+	return totals, err
+}
+
+// GetAnalyticsOverview sums up the clicks on all of the user's links over a
+// period of days and compares them with the period just before it
+func GetAnalyticsOverview(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var query dtos.AnalyticsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// Days are UTC and both ends are included; default to the last 30 days
+	to := query.To.UTC()
+	if query.To.IsZero() {
+		to = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	from := query.From.UTC()
+	if query.From.IsZero() {
+		from = to.AddDate(0, 0, -(analyticsDefaultDays - 1))
+	}
+	end := to.AddDate(0, 0, 1)
+	if !from.Before(end) {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "from must not be after to",
+		})
+		return
+	}
+	if end.Sub(from) > analyticsMaxDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "The period can't be longer than 366 days",
+		})
+		return
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = analyticsDefaultLimit
+	}
+
+	overview := dtos.AnalyticsOverviewResponse{
+		From: from.Format(analyticsDateLayout),
+		To:   to.Format(analyticsDateLayout),
+	}
+
+	// The previous period has the same length and ends where this one starts
+	previousFrom := from.Add(-end.Sub(from))
+
+	var err error
+	if overview.Totals, err = analyticsTotals(user.ID, from, end); err == nil {
+		overview.Previous, err = analyticsTotals(user.ID, previousFrom, from)
+	}
+	if err == nil {
+		overview.TopLinks, err = analyticsTopLinks(user.ID, from, end, limit)
+	}
+	if err == nil {
+		overview.TopReferrers, err = analyticsTopValues(user.ID, models.RollupDimensionReferrer, from, end, limit)
+	}
+	if err == nil {
+		overview.TopCountries, err = analyticsTopValues(user.ID, models.RollupDimensionCountry, from, end, limit)
+	}
+	if err == nil {
+		overview.TopDevices, err = analyticsTopValues(user.ID, models.RollupDimensionDevice, from, end, limit)
+	}
+	if err == nil {
+		overview.TopBrowsers, err = analyticsTopValues(user.ID, models.RollupDimensionBrowser, from, end, limit)
+	}
+	if err == nil {
+		overview.TopOS, err = analyticsTopValues(user.ID, models.RollupDimensionOS, from, end, limit)
+	}
+	if err == nil {
+		overview.Daily, err = analyticsDaily(user.ID, from, end)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch analytics",
+		})
+		return
+	}
+
+	overview.Change = dtos.AnalyticsChange{
+		Clicks:   percentChange(overview.Totals.Clicks, overview.Previous.Clicks),
+		Visits:   percentChange(overview.Totals.Visits, overview.Previous.Visits),
+		Visitors: percentChange(overview.Totals.Visitors, overview.Previous.Visitors),
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    overview,
+	})
+}
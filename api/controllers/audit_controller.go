@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// auditEntry describes an action to be written to the audit log
+type auditEntry struct {
+	Action     string
+	ActorID    *uint
+	TargetType string
+	TargetID   *uint
+	Before     map[string]interface{}
+	After      map[string]interface{}
+	Metadata   map[string]interface{}
+}
+
+// auditCursor is the position encoded in audit log cursors
+type auditCursor struct {
+	ID uint `json:"id"`
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+// auditTime formats an optional timestamp so snapshots compare by instant
+func auditTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// linkAuditSnapshot returns the audited fields of a link
+func linkAuditSnapshot(link models.Link) map[string]interface{} {
+	return map[string]interface{}{
+		"shortCode":   link.ShortCode,
+		"domainId":    link.DomainID,
+		"originalUrl": link.OriginalURL,
+		"activeFrom":  auditTime(link.ActiveFrom),
+		"activeUntil": auditTime(link.ActiveUntil),
+		"fallbackUrl": link.FallbackURL,
+		"tags":        tagNames(link.Tags),
+		"folderId":    link.FolderID,
+		"userId":      link.UserID,
+	}
+}
+
+// auditDiff returns the fields that differ between before and after
+func auditDiff(before, after map[string]interface{}) map[string]interface{} {
+	keys := map[string]struct{}{}
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	diff := map[string]interface{}{}
+	for _, k := range sorted {
+		b, hasBefore := before[k]
+		a, hasAfter := after[k]
+		if hasBefore && hasAfter && reflect.DeepEqual(b, a) {
+			continue
+		}
+		diff[k] = gin.H{"before": b, "after": a}
+	}
+	return diff
+}
+
+// jsonColumn marshals v for a jsonb column, returning nil for empty values
+func jsonColumn(v map[string]interface{}) *string {
+	if len(v) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	s := string(raw)
+	return &s
+}
+
+// recordAuditEvent appends an event to the audit log through db. Pass the
+// transaction making the audited change so the event is kept only if the change
+// is; failures are logged and returned so that transaction can roll back.
+func recordAuditEvent(db *gorm.DB, c *gin.Context, entry auditEntry) error {
+	event := models.AuditEvent{
+		Action:     entry.Action,
+		ActorID:    entry.ActorID,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		Changes:    jsonColumn(auditDiff(entry.Before, entry.After)),
+		Metadata:   jsonColumn(entry.Metadata),
+	}
+
+	if err := db.Create(&event).Error; err != nil {
+		log.Printf("Failed to record audit event %s: %v", entry.Action, err)
+		return err
+	}
+	return nil
+}
+
+func GetAuditEvents(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var query dtos.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	db := initializers.DB.Model(&models.AuditEvent{})
+
+	// Regular users only see events they performed or that targeted their account
+	if user.Role != models.RoleAdmin {
+		db = db.Where("actor_id = ? OR (target_type = ? AND target_id = ?)", user.ID, models.AuditTargetUser, user.ID)
+	}
+
+	// Apply filters
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.ActorID != nil {
+		db = db.Where("actor_id = ?", *query.ActorID)
+	}
+	if query.TargetType != "" {
+		db = db.Where("target_type = ?", query.TargetType)
+	}
+	if query.TargetID != nil {
+		db = db.Where("target_id = ?", *query.TargetID)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("created_at < ?", query.To)
+	}
+
+	// Resume after the cursor position (newest first)
+	if query.Cursor != "" {
+		var cursor auditCursor
+		if err := decodeCursor(query.Cursor, &cursor); err != nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Invalid cursor",
+			})
+			return
+		}
+		db = db.Where("id < ?", cursor.ID)
+	}
+
+	// Fetch one extra row to know whether there is another page
+	limit := pageLimit(query.Limit)
+	var events []models.AuditEvent
+	if err := db.Order("id DESC").Limit(limit + 1).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch audit events",
+		})
+		return
+	}
+
+	paging := dtos.PageInfo{Limit: limit}
+	if len(events) > limit {
+		events = events[:limit]
+		paging.HasMore = true
+		paging.NextCursor = encodeCursor(auditCursor{ID: events[len(events)-1].ID})
+	}
+
+	eventResponses := make([]dtos.AuditEventResponse, 0, len(events))
+	for _, event := range events {
+		response := dtos.AuditEventResponse{
+			ID:         event.ID,
+			Action:     event.Action,
+			ActorID:    event.ActorID,
+			TargetType: event.TargetType,
+			TargetID:   event.TargetID,
+			IP:         event.IP,
+			UserAgent:  event.UserAgent,
+			CreatedAt:  event.CreatedAt,
+		}
+		if event.Changes != nil {
+			response.Changes = json.RawMessage(*event.Changes)
+		}
+		if event.Metadata != nil {
+			response.Metadata = json.RawMessage(*event.Metadata)
+		}
+		eventResponses = append(eventResponses, response)
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    eventResponses,
+		Paging:  &paging,
+	})
+}
@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caiohportella/blinky/domains"
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const domainVerificationTimeout = 10 * time.Second
+
+func toDomainResponse(domain models.Domain) dtos.DomainResponse {
+	return dtos.DomainResponse{
+		ID:         domain.ID,
+		Hostname:   domain.Hostname,
+		Verified:   domain.Verified(),
+		VerifiedAt: domain.VerifiedAt,
+		Verification: dtos.DomainVerificationRecord{
+			Type:  "TXT",
+			Name:  domains.RecordName(domain.Hostname),
+			Value: domains.RecordValue(domain.VerificationToken),
+		},
+		CreatedAt: domain.CreatedAt,
+	}
+}
+
+// findUserDomain loads a domain by the :id URL param and checks that it belongs to user,
+// writing the error response if it doesn't
+func findUserDomain(c *gin.Context, user models.User) (models.Domain, bool) {
+	domainID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid domain ID",
+		})
+		return models.Domain{}, false
+	}
+
+	var domain models.Domain
+	if err := initializers.DB.Where("user_id = ?", user.ID).First(&domain, domainID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Domain not found",
+		})
+		return models.Domain{}, false
+	}
+
+	return domain, true
+}
+
+// verifiedDomainForHost returns the verified custom domain serving host, if any
+func verifiedDomainForHost(host string) (*models.Domain, error) {
+	var domain models.Domain
+	err := initializers.DB.Where("hostname = ? AND verified_at IS NOT NULL", domains.NormalizeHost(host)).First(&domain).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+func GetDomains(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var userDomains []models.Domain
+	if err := initializers.DB.Where("user_id = ?", user.ID).Order("hostname").Find(&userDomains).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch domains",
+		})
+		return
+	}
+
+	domainResponses := make([]dtos.DomainResponse, 0, len(userDomains))
+	for _, domain := range userDomains {
+		domainResponses = append(domainResponses, toDomainResponse(domain))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    domainResponses,
+	})
+}
+
+func CreateDomain(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var req dtos.CreateDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// A hostname belongs to whoever verified it first. Pending claims don't
+	// block anyone, so nobody can squat a domain they don't control.
+	hostname := domains.NormalizeHost(req.Hostname)
+	var existing models.Domain
+	err := initializers.DB.Where("hostname = ? AND (verified_at IS NOT NULL OR user_id = ?)", hostname, user.ID).First(&existing).Error
+	if err == nil {
+		message := "Domain is already registered"
+		if existing.UserID == user.ID {
+			message = "You have already added this domain"
+		}
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check domain availability",
+		})
+		return
+	}
+
+	token, err := domains.GenerateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate verification token",
+		})
+		return
+	}
+
+	domain := models.Domain{
+		Hostname:          hostname,
+		UserID:            user.ID,
+		VerificationToken: token,
+	}
+	if err := initializers.DB.Create(&domain).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, dtos.ErrorResponse{
+				Success: false,
+				Error:   "You have already added this domain",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create domain",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    toDomainResponse(domain),
+		Message: "Add the TXT record below to your DNS, then verify the domain",
+	})
+}
+
+func VerifyDomain(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	domain, found := findUserDomain(c, user)
+	if !found {
+		return
+	}
+
+	if domain.Verified() {
+		c.JSON(http.StatusOK, dtos.SuccessResponse{
+			Success: true,
+			Data:    toDomainResponse(domain),
+			Message: "Domain is already verified",
+		})
+		return
+	}
+
+	// Look for the verification TXT record
+	ctx, cancel := context.WithTimeout(c.Request.Context(), domainVerificationTimeout)
+	defer cancel()
+	verified, err := domains.Verify(ctx, domains.Resolver, domain.Hostname, domain.VerificationToken)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, dtos.ErrorResponse{
+			Success: false,
+			Error:   "DNS lookup failed: " + err.Error(),
+		})
+		return
+	}
+	if !verified {
+		c.JSON(http.StatusUnprocessableEntity, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Verification TXT record not found at " + domains.RecordName(domain.Hostname),
+		})
+		return
+	}
+
+	// The first claim to verify wins and the other pending claims are dropped;
+	// a concurrent verification of another claim trips the unique index
+	now := time.Now()
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain).Update("verified_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Where("hostname = ? AND id <> ? AND verified_at IS NULL", domain.Hostname, domain.ID).Delete(&models.Domain{}).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Domain has already been verified by another account",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to verify domain",
+		})
+		return
+	}
+	domain.VerifiedAt = &now
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toDomainResponse(domain),
+		Message: "Domain verified successfully",
+	})
+}
+
+func DeleteDomain(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	domain, found := findUserDomain(c, user)
+	if !found {
+		return
+	}
+
+	// Links keep their short codes scoped to the domain, so it can't go while they exist
+	var linkCount int64
+	if err := initializers.DB.Unscoped().Model(&models.Link{}).Where("domain_id = ?", domain.ID).Count(&linkCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check domain links",
+		})
+		return
+	}
+	if linkCount > 0 {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Domain still has links, including trashed ones; delete them first",
+		})
+		return
+	}
+
+	if err := initializers.DB.Delete(&domain).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete domain",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Message: "Domain deleted successfully",
+	})
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetFavicon serves a stored favicon (no auth required). Favicons are
+// content-addressed, so they never change and can be cached for good.
+func GetFavicon(c *gin.Context) {
+	faviconID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid favicon ID",
+		})
+		return
+	}
+
+	var favicon models.Favicon
+	if err := initializers.DB.First(&favicon, faviconID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Favicon not found",
+		})
+		return
+	}
+
+	etag := `"` + favicon.Hash[:16] + `"`
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("ETag", etag)
+	// Icons come from third-party sites; never let one be interpreted as a document
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, favicon.ContentType, favicon.Data)
+}
@@ -0,0 +1,406 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// folderOwnedBy reports whether the folder exists and belongs to userID
+func folderOwnedBy(tx *gorm.DB, folderID, userID uint) (bool, error) {
+	var folder models.Folder
+	err := tx.Where("user_id = ?", userID).First(&folder, folderID).Error
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// folderNameTaken reports whether another of the user's folders with the same
+// parent already has this name
+func folderNameTaken(userID uint, parentID *uint, name string, exceptID uint) (bool, error) {
+	var parentKey uint
+	if parentID != nil {
+		parentKey = *parentID
+	}
+
+	var existing models.Folder
+	err := initializers.DB.
+		Where("user_id = ? AND COALESCE(parent_id, 0) = ? AND name = ? AND id <> ?", userID, parentKey, name, exceptID).
+		First(&existing).Error
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// respondFolderExists reports a name clash with a folder in the same parent
+func respondFolderExists(c *gin.Context) {
+	c.JSON(http.StatusConflict, dtos.ErrorResponse{
+		Success: false,
+		Error:   "A folder with this name already exists here",
+	})
+}
+
+// folderIsDescendant reports whether candidate is folderID itself or one of its subfolders
+func folderIsDescendant(candidate, folderID uint) (bool, error) {
+	current := &candidate
+	for current != nil {
+		if *current == folderID {
+			return true, nil
+		}
+		var folder models.Folder
+		if err := initializers.DB.Select("id", "parent_id").First(&folder, *current).Error; err != nil {
+			return false, err
+		}
+		current = folder.ParentID
+	}
+	return false, nil
+}
+
+// findUserFolder loads a folder by the :id URL param and checks that it belongs to user,
+// writing the error response if it doesn't
+func findUserFolder(c *gin.Context, user models.User) (models.Folder, bool) {
+	folderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid folder ID",
+		})
+		return models.Folder{}, false
+	}
+
+	var folder models.Folder
+	if err := initializers.DB.Where("user_id = ?", user.ID).First(&folder, folderID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Folder not found",
+		})
+		return models.Folder{}, false
+	}
+
+	return folder, true
+}
+
+func toFolderResponse(folder models.Folder) dtos.FolderResponse {
+	return dtos.FolderResponse{
+		ID:        folder.ID,
+		Name:      folder.Name,
+		ParentID:  folder.ParentID,
+		CreatedAt: folder.CreatedAt,
+	}
+}
+
+func GetFolders(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Fetch the flat folder list; clients build the tree from parentId
+	var folders []dtos.FolderResponse
+	err := initializers.DB.Model(&models.Folder{}).
+		Select("folders.id, folders.name, folders.parent_id, folders.created_at, COUNT(links.id) AS link_count").
+		Joins("LEFT JOIN links ON links.folder_id = folders.id AND links.deleted_at IS NULL").
+		Where("folders.user_id = ?", user.ID).
+		Group("folders.id").
+		Order("folders.name").
+		Scan(&folders).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch folders",
+		})
+		return
+	}
+
+	if folders == nil {
+		folders = []dtos.FolderResponse{}
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    folders,
+	})
+}
+
+func CreateFolder(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var req dtos.CreateFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if req.ParentID != nil {
+		owned, err := folderOwnedBy(initializers.DB, *req.ParentID, user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to check parent folder",
+			})
+			return
+		}
+		if !owned {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Parent folder not found",
+			})
+			return
+		}
+	}
+
+	folder := models.Folder{
+		Name:     strings.TrimSpace(req.Name),
+		UserID:   user.ID,
+		ParentID: req.ParentID,
+	}
+	taken, err := folderNameTaken(user.ID, folder.ParentID, folder.Name, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check folder name",
+		})
+		return
+	}
+	if taken {
+		respondFolderExists(c)
+		return
+	}
+
+	err = initializers.DB.Create(&folder).Error
+	// A concurrent request may have taken the name since the check above
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		respondFolderExists(c)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create folder",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    toFolderResponse(folder),
+	})
+}
+
+func UpdateFolder(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	folder, found := findUserFolder(c, user)
+	if !found {
+		return
+	}
+
+	var req dtos.UpdateFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Name != nil {
+		folder.Name = strings.TrimSpace(*req.Name)
+	}
+
+	// Move the folder, refusing to put it inside itself or one of its subfolders
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			folder.ParentID = nil
+		} else {
+			owned, err := folderOwnedBy(initializers.DB, *req.ParentID, user.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+					Success: false,
+					Error:   "Failed to check parent folder",
+				})
+				return
+			}
+			if !owned {
+				c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+					Success: false,
+					Error:   "Parent folder not found",
+				})
+				return
+			}
+
+			cycle, err := folderIsDescendant(*req.ParentID, folder.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+					Success: false,
+					Error:   "Failed to check parent folder",
+				})
+				return
+			}
+			if cycle {
+				c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+					Success: false,
+					Error:   "A folder can't be moved into itself or one of its subfolders",
+				})
+				return
+			}
+
+			folder.ParentID = req.ParentID
+		}
+	}
+
+	taken, err := folderNameTaken(user.ID, folder.ParentID, folder.Name, folder.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check folder name",
+		})
+		return
+	}
+	if taken {
+		respondFolderExists(c)
+		return
+	}
+
+	err = initializers.DB.Model(&folder).Select("name", "parent_id").Updates(&folder).Error
+	// A concurrent request may have taken the name since the check above
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		respondFolderExists(c)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update folder",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toFolderResponse(folder),
+	})
+}
+
+func DeleteFolder(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	folder, found := findUserFolder(c, user)
+	if !found {
+		return
+	}
+
+	// Move links and subfolders up to the parent, then delete the folder
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Link{}).Where("folder_id = ?", folder.ID).Update("folder_id", folder.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Folder{}).Where("parent_id = ?", folder.ID).Update("parent_id", folder.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&folder).Error
+	})
+	// Subfolders keep their names, which may clash with the parent's other folders
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "A subfolder has the same name as a folder it would be moved next to; rename it first",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete folder",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Message: "Folder deleted successfully",
+	})
+}
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"gorm.io/gorm"
+)
+
+// maxCodeAttempts bounds how many generated codes are tried for one link
+const maxCodeAttempts = 10
+
+// errShortCodeTaken is returned by createLink when another link got the short
+// code between the availability check and the insert
+var errShortCodeTaken = errors.New("short code already exists")
+
+// linkError is a link validation failure together with the HTTP status to report
+type linkError struct {
+	Status  int
+	Message string
+}
+
+func (e *linkError) Error() string {
+	return e.Message
+}
+
+// shortCodeTaken reports whether a short code is already used on a domain, ignoring
+// case and including trashed links. A nil domainID stands for the default short domain.
+func shortCodeTaken(tx *gorm.DB, domainID *uint, shortCode string) (bool, error) {
+	// Matches the expression of idx_links_domain_lower_short_code so the index is used
+	var domainKey uint
+	if domainID != nil {
+		domainKey = *domainID
+	}
+
+	var existingLink models.Link
+	err := tx.Unscoped().
+		Where("COALESCE(domain_id, 0) = ? AND LOWER(short_code) = LOWER(?)", domainKey, shortCode).
+		First(&existingLink).Error
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// reservationKey identifies a short code on a domain within a batch, ignoring case
+func reservationKey(domainID *uint, shortCode string) string {
+	shortCode = strings.ToLower(shortCode)
+	if domainID == nil {
+		return "/" + shortCode
+	}
+	return strconv.FormatUint(uint64(*domainID), 10) + "/" + shortCode
+}
+
+// generateShortCode asks the code generator for codes until one is free on the domain.
+// Only codes that are taken count as collisions and make the generator grow its
+// codes; codes containing a blocked word are just replaced.
+func generateShortCode(tx *gorm.DB, domainID *uint, reserved map[string]bool) (string, *linkError) {
+	collisions, attempt := 0, 0
+	for candidate := 0; candidate < maxCodeAttempts; candidate++ {
+		shortCode, err := initializers.CodeGenerator.Generate(attempt)
+		if err != nil {
+			return "", &linkError{http.StatusInternalServerError, "Failed to generate short code"}
+		}
+		if initializers.CodeValidator.CheckWords(shortCode) != nil {
+			attempt = 0
+			continue
+		}
+
+		taken := reserved[reservationKey(domainID, shortCode)]
+		if !taken {
+			if taken, err = shortCodeTaken(tx, domainID, shortCode); err != nil {
+				return "", &linkError{http.StatusInternalServerError, "Failed to check short code availability"}
+			}
+		}
+		if !taken {
+			return shortCode, nil
+		}
+		collisions++
+		attempt = collisions
+	}
+	return "", &linkError{http.StatusInternalServerError, "Failed to find a free short code"}
+}
+
+// screenURL runs a destination through the URL safety policy
+func screenURL(originalURL string) *linkError {
+	if rejection := initializers.URLPolicy().Check(originalURL); rejection != nil {
+		return &linkError{http.StatusUnprocessableEntity, "Destination URL rejected: " + rejection.Reason}
+	}
+	return nil
+}
+
+// buildLink validates req and prepares a new link owned by userID without saving it.
+// reserved holds the short codes already claimed by other links of the same batch
+// and is updated with the code picked for this one.
+func buildLink(tx *gorm.DB, userID uint, req dtos.CreateLinkRequest, reserved map[string]bool) (models.Link, *linkError) {
+	if err := screenURL(req.OriginalURL); err != nil {
+		return models.Link{}, err
+	}
+
+	// Custom domains must belong to the user and be verified
+	if req.DomainID != nil {
+		var domain models.Domain
+		if err := tx.Where("user_id = ?", userID).First(&domain, *req.DomainID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.Link{}, &linkError{http.StatusBadRequest, "Domain not found"}
+			}
+			return models.Link{}, &linkError{http.StatusInternalServerError, "Failed to check domain"}
+		}
+		if !domain.Verified() {
+			return models.Link{}, &linkError{http.StatusBadRequest, "Domain is not verified yet"}
+		}
+	}
+
+	// Use the custom short code, or generate one, retrying on collisions
+	shortCode := req.CustomCode
+	if shortCode != "" {
+		if err := initializers.CodeValidator.Validate(shortCode); err != nil {
+			return models.Link{}, &linkError{http.StatusBadRequest, err.Error()}
+		}
+		if reserved[reservationKey(req.DomainID, shortCode)] {
+			return models.Link{}, &linkError{http.StatusConflict, "Short code is used more than once in this batch"}
+		}
+		taken, err := shortCodeTaken(tx, req.DomainID, shortCode)
+		if err != nil {
+			return models.Link{}, &linkError{http.StatusInternalServerError, "Failed to check short code availability"}
+		}
+		if taken {
+			return models.Link{}, &linkError{http.StatusConflict, "Short code already exists"}
+		}
+	} else {
+		var codeErr *linkError
+		if shortCode, codeErr = generateShortCode(tx, req.DomainID, reserved); codeErr != nil {
+			return models.Link{}, codeErr
+		}
+	}
+	key := reservationKey(req.DomainID, shortCode)
+
+	if req.ActiveUntil != nil && !req.ActiveUntil.After(time.Now()) {
+		return models.Link{}, &linkError{http.StatusBadRequest, "Expiry must be in the future"}
+	}
+	if req.ActiveFrom != nil && req.ActiveUntil != nil && !req.ActiveUntil.After(*req.ActiveFrom) {
+		return models.Link{}, &linkError{http.StatusBadRequest, "Activation window must end after it starts"}
+	}
+	if req.FallbackURL != "" {
+		if err := screenURL(req.FallbackURL); err != nil {
+			return models.Link{}, &linkError{err.Status, "Fallback " + err.Message}
+		}
+	}
+
+	if req.FolderID != nil {
+		owned, err := folderOwnedBy(tx, *req.FolderID, userID)
+		if err != nil {
+			return models.Link{}, &linkError{http.StatusInternalServerError, "Failed to check folder"}
+		}
+		if !owned {
+			return models.Link{}, &linkError{http.StatusBadRequest, "Folder not found"}
+		}
+	}
+
+	if reserved != nil {
+		reserved[key] = true
+	}
+
+	// Tags are resolved to rows when the link is saved
+	var tags []models.Tag
+	for _, name := range normalizeTagNames(req.Tags) {
+		tags = append(tags, models.Tag{Name: name, UserID: userID})
+	}
+
+	return models.Link{
+		ShortCode:   shortCode,
+		DomainID:    req.DomainID,
+		OriginalURL: req.OriginalURL,
+		ActiveFrom:  req.ActiveFrom,
+		ActiveUntil: req.ActiveUntil,
+		FallbackURL: req.FallbackURL,
+		UserID:      userID,
+		FolderID:    req.FolderID,
+		Clicks:      0,
+		Tags:        tags,
+
+		QueryPassthrough: req.QueryPassthrough,
+		QueryPrecedence:  req.QueryPrecedence,
+		UTMSource:        req.UTMSource,
+		UTMMedium:        req.UTMMedium,
+		UTMCampaign:      req.UTMCampaign,
+		UTMTerm:          req.UTMTerm,
+		UTMContent:       req.UTMContent,
+	}, nil
+}
+
+// normalizeTagNames trims, lowercases and de-duplicates tag names
+func normalizeTagNames(names []string) []string {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// resolveTags replaces unsaved tags with the user's existing tags of the same
+// name, creating the ones that don't exist yet
+func resolveTags(tx *gorm.DB, tags []models.Tag) ([]models.Tag, error) {
+	resolved := make([]models.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if tag.ID == 0 {
+			if err := tx.Where(models.Tag{Name: tag.Name, UserID: tag.UserID}).FirstOrCreate(&tag).Error; err != nil {
+				return nil, err
+			}
+		}
+		resolved = append(resolved, tag)
+	}
+	return resolved, nil
+}
+
+// createLink saves a link prepared by buildLink together with its tags, and
+// records its settings as the first version of its history. If the short code
+// was claimed since buildLink checked it, a generated code is replaced with a
+// new one, while a custom code fails with errShortCodeTaken.
+func createLink(tx *gorm.DB, link *models.Link, generatedCode bool) error {
+	tags, err := resolveTags(tx, link.Tags)
+	if err != nil {
+		return err
+	}
+	link.Tags = tags
+	link.Version = 1
+
+	for attempt := 1; ; attempt++ {
+		// The savepoint keeps tx usable after a failed insert
+		err := tx.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Omit("Tags.*").Create(link).Error; err != nil {
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					return errShortCodeTaken
+				}
+				return err
+			}
+			version := linkVersionOf(*link)
+			version.AuthorID = &link.UserID
+			return tx.Create(&version).Error
+		})
+		if !errors.Is(err, errShortCodeTaken) || !generatedCode || attempt == maxCodeAttempts {
+			return err
+		}
+
+		shortCode, codeErr := generateShortCode(tx, link.DomainID, nil)
+		if codeErr != nil {
+			return codeErr
+		}
+		link.ShortCode = shortCode
+	}
+}
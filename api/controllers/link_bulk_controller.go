@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// summarizeBulkResults counts the outcomes of a bulk operation
+func summarizeBulkResults(results []dtos.BulkItemResult) dtos.BulkResponse {
+	response := dtos.BulkResponse{Results: results}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	return response
+}
+
+// respondBulkRejected reports an atomic batch that was not applied because some items failed
+func respondBulkRejected(c *gin.Context, results []dtos.BulkItemResult) {
+	c.JSON(http.StatusUnprocessableEntity, dtos.SuccessResponse{
+		Success: false,
+		Data:    summarizeBulkResults(results),
+		Message: "Batch rejected: no changes were applied because some items are invalid",
+	})
+}
+
+func BulkCreateLinks(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	if rejectFlaggedUser(c, user) {
+		return
+	}
+
+	// Parse request body; items are validated one by one below
+	var req dtos.BulkCreateLinksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// Validate every item and prepare the links, reserving short codes within the batch
+	results := make([]dtos.BulkItemResult, len(req.Links))
+	links := make([]*models.Link, len(req.Links))
+	reserved := map[string]bool{}
+	for i, item := range req.Links {
+		results[i].Index = i
+
+		if err := binding.Validator.ValidateStruct(&item); err != nil {
+			results[i].Error = "Invalid input: " + err.Error()
+			continue
+		}
+
+		link, linkErr := buildLink(initializers.DB, user.ID, item, reserved)
+		if linkErr != nil {
+			results[i].Error = linkErr.Message
+			continue
+		}
+		links[i] = &link
+	}
+
+	// createAudited creates the link of item i, recording its audit event and
+	// queueing its webhook event in tx
+	createAudited := func(tx *gorm.DB, i int, link *models.Link) error {
+		if err := createLink(tx, link, req.Links[i].CustomCode == ""); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkCreate,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			After:      linkAuditSnapshot(*link),
+			Metadata:   map[string]interface{}{"bulk": true},
+		}); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, user.ID, models.WebhookEventLinkCreated, webhooks.NewLinkData(*link))
+	}
+
+	if req.Atomic {
+		for _, result := range results {
+			if result.Error != "" {
+				respondBulkRejected(c, results)
+				return
+			}
+		}
+
+		// All or nothing: create every link in a single transaction
+		err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+			for i, link := range links {
+				if err := createAudited(tx, i, link); err != nil {
+					if errors.Is(err, errShortCodeTaken) {
+						results[i].Error = "Short code already exists"
+					}
+					return err
+				}
+			}
+			return nil
+		})
+		if errors.Is(err, errShortCodeTaken) {
+			respondBulkRejected(c, results)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to create links",
+			})
+			return
+		}
+	} else {
+		// Best effort: create links independently
+		for i, link := range links {
+			if link == nil {
+				continue
+			}
+			err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+				return createAudited(tx, i, link)
+			})
+			if errors.Is(err, errShortCodeTaken) {
+				results[i].Error = "Short code already exists"
+				links[i] = nil
+			} else if err != nil {
+				results[i].Error = "Failed to create link"
+				links[i] = nil
+			}
+		}
+	}
+
+	for i, link := range links {
+		if link == nil {
+			continue
+		}
+
+		response := toLinkResponse(*link)
+		publishLinkEvent(user.ID, streamEventLinkCreated, link.ID, response)
+		results[i].ID = link.ID
+		results[i].Success = true
+		results[i].Link = &response
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    summarizeBulkResults(results),
+	})
+}
+
+func BulkDeleteLinks(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Parse request body
+	var req dtos.BulkDeleteLinksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// Load all requested links at once
+	var found []models.Link
+	if err := initializers.DB.Preload("Tags").Where("id IN ?", req.IDs).Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch links",
+		})
+		return
+	}
+	linksByID := make(map[uint]models.Link, len(found))
+	for _, link := range found {
+		linksByID[link.ID] = link
+	}
+
+	// Check existence and ownership of every link
+	results := make([]dtos.BulkItemResult, len(req.IDs))
+	links := make([]*models.Link, len(req.IDs))
+	seen := map[uint]bool{}
+	for i, id := range req.IDs {
+		results[i].Index = i
+		results[i].ID = id
+
+		link, exists := linksByID[id]
+		switch {
+		case seen[id]:
+			results[i].Error = "Link is listed more than once in this batch"
+		case !exists:
+			results[i].Error = "Link not found"
+		case link.UserID != user.ID:
+			results[i].Error = "You don't have permission to delete this link"
+		default:
+			links[i] = &link
+		}
+		seen[id] = true
+	}
+
+	// deleteAudited moves link to the trash, recording its audit event and
+	// queueing its webhook event in tx
+	deleteAudited := func(tx *gorm.DB, link *models.Link) error {
+		if err := tx.Delete(link).Error; err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkDelete,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			Before:     linkAuditSnapshot(*link),
+			Metadata:   map[string]interface{}{"bulk": true},
+		}); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, user.ID, models.WebhookEventLinkDeleted, webhooks.NewLinkData(*link))
+	}
+
+	if req.Atomic {
+		for _, result := range results {
+			if result.Error != "" {
+				respondBulkRejected(c, results)
+				return
+			}
+		}
+
+		// All or nothing: delete every link in a single transaction
+		err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+			for _, link := range links {
+				if err := deleteAudited(tx, link); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to delete links",
+			})
+			return
+		}
+	} else {
+		// Best effort: delete links independently
+		for i, link := range links {
+			if link == nil {
+				continue
+			}
+			err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+				return deleteAudited(tx, link)
+			})
+			if err != nil {
+				results[i].Error = "Failed to delete link"
+				links[i] = nil
+			}
+		}
+	}
+
+	for i, link := range links {
+		if link == nil {
+			continue
+		}
+
+		invalidateLinkCaches(link.ID)
+		publishLinkEvent(user.ID, streamEventLinkDeleted, link.ID, gin.H{"id": link.ID})
+
+		results[i].Success = true
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    summarizeBulkResults(results),
+	})
+}
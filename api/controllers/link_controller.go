@@ -1,36 +1,123 @@
 package controllers
 
 import (
-	"crypto/rand"
-	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"net/http"
-	"net/url"
 	"strconv"
+	"time"
 
+	"github.com/caiohportella/blinky/botfilter"
 	"github.com/caiohportella/blinky/dtos"
 	"github.com/caiohportella/blinky/initializers"
 	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/webhooks"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-// generateShortCode creates a random 7-character short code
-func generateShortCode() (string, error) {
-	bytes := make([]byte, 6)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
+// shortLinkURL returns the public short URL of link, loading its custom domain if needed
+func shortLinkURL(link models.Link) (string, error) {
+	if link.DomainID == nil {
+		return initializers.ShortURLBase() + "/" + link.ShortCode, nil
 	}
-	return base64.URLEncoding.EncodeToString(bytes)[:7], nil
+
+	domain := link.Domain
+	if domain == nil {
+		domain = &models.Domain{}
+		if err := initializers.DB.First(domain, *link.DomainID).Error; err != nil {
+			return "", err
+		}
+	}
+	return "https://" + domain.Hostname + "/" + link.ShortCode, nil
 }
 
-// getFaviconURL returns the Google favicon service URL for a domain
-func getFaviconURL(originalURL string) string {
-	parsed, err := url.Parse(originalURL)
-	if err != nil {
-		return ""
+// invalidateLinkCaches drops anything cached for a link after it changed
+func invalidateLinkCaches(linkID uint) {
+	qrCache.Invalidate(linkID)
+}
+
+// setDestination points link at originalURL, queueing the new destination for a
+// metadata fetch and a health check
+func setDestination(link *models.Link, originalURL string) {
+	link.OriginalURL = originalURL
+
+	link.Title = ""
+	link.Description = ""
+	link.ImageURL = ""
+	link.FaviconID = nil
+	link.MetadataFetchedAt = nil
+
+	link.HealthCheckedAt = nil
+	link.HealthNextCheckAt = nil
+	link.HealthStatusCode = 0
+	link.HealthLatencyMs = 0
+	link.HealthRedirectChain = nil
+	link.HealthError = ""
+	link.HealthFailures = 0
+	link.BrokenAt = nil
+}
+
+// tagNames returns the names of tags
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// toLinkResponse converts a link model to its API representation
+func toLinkResponse(link models.Link) dtos.LinkResponse {
+	response := dtos.LinkResponse{
+		ID:               link.ID,
+		ShortCode:        link.ShortCode,
+		DomainID:         link.DomainID,
+		OriginalURL:      link.OriginalURL,
+		Clicks:           link.Clicks,
+		LastClickedAt:    link.LastClickedAt,
+		ActiveFrom:       link.ActiveFrom,
+		ActiveUntil:      link.ActiveUntil,
+		FallbackURL:      link.FallbackURL,
+		Status:           link.Status(time.Now()),
+		Version:          link.Version,
+		Broken:           link.BrokenAt != nil,
+		Tags:             tagNames(link.Tags),
+		FolderID:         link.FolderID,
+		DisabledAt:       link.DisabledAt,
+		DisabledReason:   link.DisabledReason,
+		StickyRotation:   link.StickyRotation,
+		QueryPassthrough: link.QueryPassthrough,
+		QueryPrecedence:  link.QueryPrecedence,
+		UTMParams: dtos.UTMParams{
+			UTMSource:   link.UTMSource,
+			UTMMedium:   link.UTMMedium,
+			UTMCampaign: link.UTMCampaign,
+			UTMTerm:     link.UTMTerm,
+			UTMContent:  link.UTMContent,
+		},
+		Title:       link.Title,
+		Description: link.Description,
+		Image:       link.ImageURL,
+		UserID:      link.UserID,
+		CreatedAt:   link.CreatedAt,
+	}
+	if link.HealthCheckedAt != nil {
+		response.Health = &dtos.LinkHealthResponse{
+			CheckedAt:   *link.HealthCheckedAt,
+			StatusCode:  link.HealthStatusCode,
+			LatencyMs:   link.HealthLatencyMs,
+			Error:       link.HealthError,
+			BrokenSince: link.BrokenAt,
+		}
+		if link.HealthRedirectChain != nil {
+			response.Health.RedirectChain = json.RawMessage(*link.HealthRedirectChain)
+		}
 	}
-	return "https://www.google.com/s2/favicons?domain=" + parsed.Host + "&sz=128"
+	if link.FaviconID != nil {
+		response.Favicon = initializers.APIPublicURL() + "/favicons/" + strconv.FormatUint(uint64(*link.FaviconID), 10)
+	}
+	return response
 }
 
 func GetLinks(c *gin.Context) {
@@ -53,9 +140,36 @@ func GetLinks(c *gin.Context) {
 		return
 	}
 
-	// Fetch links for the user
+	var query dtos.LinkQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+	if query.Sort == "" {
+		query.Sort = "created"
+	}
+	if query.Order == "" {
+		query.Order = "desc"
+	}
+
+	// Build the filtered and ordered query for the user's links
+	db := applyLinkFilters(initializers.DB.Where("links.user_id = ?", user.ID), query)
+	db, err := applyLinkOrder(db, query.Sort, query.Order, query.Cursor)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid cursor",
+		})
+		return
+	}
+
+	// Fetch one extra row to know whether there is another page
+	limit := pageLimit(query.Limit)
 	var links []models.Link
-	if err := initializers.DB.Where("user_id = ?", user.ID).Order("created_at DESC").Find(&links).Error; err != nil {
+	if err := db.Preload("Tags").Limit(limit + 1).Find(&links).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Failed to fetch links",
@@ -63,28 +177,29 @@ func GetLinks(c *gin.Context) {
 		return
 	}
 
-	// Convert to response DTOs
-	var linkResponses []dtos.LinkResponse
-	for _, link := range links {
-		linkResponses = append(linkResponses, dtos.LinkResponse{
-			ID:          link.ID,
-			ShortCode:   link.ShortCode,
-			OriginalURL: link.OriginalURL,
-			Clicks:      link.Clicks,
-			Favicon:     link.Favicon,
-			UserID:      link.UserID,
-			CreatedAt:   link.CreatedAt,
+	paging := dtos.PageInfo{Limit: limit}
+	if len(links) > limit {
+		links = links[:limit]
+		last := links[len(links)-1]
+		paging.HasMore = true
+		paging.NextCursor = encodeCursor(linkCursor{
+			Sort:  query.Sort,
+			Order: query.Order,
+			Value: linkSortValue(last, query.Sort),
+			ID:    last.ID,
 		})
 	}
 
-	// Return empty array instead of null if no links
-	if linkResponses == nil {
-		linkResponses = []dtos.LinkResponse{}
+	// Convert to response DTOs
+	linkResponses := make([]dtos.LinkResponse, 0, len(links))
+	for _, link := range links {
+		linkResponses = append(linkResponses, toLinkResponse(link))
 	}
 
 	c.JSON(http.StatusOK, dtos.SuccessResponse{
 		Success: true,
 		Data:    linkResponses,
+		Paging:  &paging,
 	})
 }
 
@@ -108,6 +223,10 @@ func CreateLink(c *gin.Context) {
 		return
 	}
 
+	if rejectFlaggedUser(c, user) {
+		return
+	}
+
 	// Parse request body
 	var req dtos.CreateLinkRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -118,68 +237,271 @@ func CreateLink(c *gin.Context) {
 		return
 	}
 
-	// Generate or use custom short code
-	shortCode := req.CustomCode
-	if shortCode == "" {
-		var err error
-		shortCode, err = generateShortCode()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
-				Success: false,
-				Error:   "Failed to generate short code",
-			})
-			return
-		}
+	// Validate the request and prepare the link
+	link, linkErr := buildLink(initializers.DB, user.ID, req, nil)
+	if linkErr != nil {
+		c.JSON(linkErr.Status, dtos.ErrorResponse{
+			Success: false,
+			Error:   linkErr.Message,
+		})
+		return
 	}
 
-	// Check if short code already exists
-	var existingLink models.Link
-	err := initializers.DB.Where("short_code = ?", shortCode).First(&existingLink).Error
-	if err == nil {
-		// Short code already exists
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := createLink(tx, &link, req.CustomCode == ""); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkCreate,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			After:      linkAuditSnapshot(link),
+		}); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, user.ID, models.WebhookEventLinkCreated, webhooks.NewLinkData(link))
+	})
+	if errors.Is(err, errShortCodeTaken) {
 		c.JSON(http.StatusConflict, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Short code already exists",
 		})
 		return
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		// Unexpected database error
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
 			Success: false,
-			Error:   "Failed to check short code availability",
+			Error:   "Failed to create link",
 		})
 		return
 	}
-	// err is gorm.ErrRecordNotFound - short code is available, continue
 
-	// Create the link
-	link := models.Link{
-		ShortCode:   shortCode,
-		OriginalURL: req.OriginalURL,
-		Favicon:     getFaviconURL(req.OriginalURL),
-		UserID:      user.ID,
-		Clicks:      0,
+	publishLinkEvent(user.ID, streamEventLinkCreated, link.ID, toLinkResponse(link))
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(link),
+	})
+}
+
+func UpdateLink(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
 	}
 
-	if err := initializers.DB.Create(&link).Error; err != nil {
+	user, ok := userInterface.(models.User)
+	if !ok {
 		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
 			Success: false,
-			Error:   "Failed to create link",
+			Error:   "Failed to get user data",
 		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+	// Get link ID from URL param
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return
+	}
+
+	// Find the link
+	var link models.Link
+	if err := initializers.DB.Preload("Tags").First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return
+	}
+
+	// Check ownership
+	if link.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You don't have permission to update this link",
+		})
+		return
+	}
+
+	// Parse request body
+	var req dtos.UpdateLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// Flagged accounts may still tidy up their links, but not redirect them
+	if (req.OriginalURL != nil || req.FallbackURL != nil) && rejectFlaggedUser(c, user) {
+		return
+	}
+
+	previous := link
+	before := linkAuditSnapshot(link)
+
+	// Apply the requested changes
+	if req.OriginalURL != nil {
+		if *req.OriginalURL != link.OriginalURL {
+			if err := screenURL(*req.OriginalURL); err != nil {
+				c.JSON(err.Status, dtos.ErrorResponse{
+					Success: false,
+					Error:   err.Message,
+				})
+				return
+			}
+
+			setDestination(&link, *req.OriginalURL)
+		}
+	}
+
+	if req.ActiveUntil.Set {
+		if req.ActiveUntil.Time != nil && !req.ActiveUntil.Time.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Expiry must be in the future",
+			})
+			return
+		}
+		link.ActiveUntil = req.ActiveUntil.Time
+	}
+
+	if req.ActiveFrom.Set {
+		link.ActiveFrom = req.ActiveFrom.Time
+	}
+	if link.ActiveFrom != nil && link.ActiveUntil != nil && !link.ActiveUntil.After(*link.ActiveFrom) {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Activation window must end after it starts",
+		})
+		return
+	}
+
+	if req.FallbackURL != nil {
+		if *req.FallbackURL != "" {
+			if err := screenURL(*req.FallbackURL); err != nil {
+				c.JSON(err.Status, dtos.ErrorResponse{
+					Success: false,
+					Error:   "Fallback " + err.Message,
+				})
+				return
+			}
+		}
+		link.FallbackURL = *req.FallbackURL
+	}
+
+	if req.QueryPassthrough != nil {
+		link.QueryPassthrough = *req.QueryPassthrough
+	}
+	if req.QueryPrecedence != nil {
+		link.QueryPrecedence = *req.QueryPrecedence
+	}
+	if req.UTMSource != nil {
+		link.UTMSource = *req.UTMSource
+	}
+	if req.UTMMedium != nil {
+		link.UTMMedium = *req.UTMMedium
+	}
+	if req.UTMCampaign != nil {
+		link.UTMCampaign = *req.UTMCampaign
+	}
+	if req.UTMTerm != nil {
+		link.UTMTerm = *req.UTMTerm
+	}
+	if req.UTMContent != nil {
+		link.UTMContent = *req.UTMContent
+	}
+
+	if req.StickyRotation != nil {
+		link.StickyRotation = *req.StickyRotation
+		if link.StickyRotation == "none" {
+			link.StickyRotation = models.StickyRotationNone
+		}
+	}
+
+	if req.FolderID != nil {
+		if *req.FolderID == 0 {
+			link.FolderID = nil
+		} else {
+			owned, err := folderOwnedBy(initializers.DB, *req.FolderID, user.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+					Success: false,
+					Error:   "Failed to check folder",
+				})
+				return
+			}
+			if !owned {
+				c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+					Success: false,
+					Error:   "Folder not found",
+				})
+				return
+			}
+			link.FolderID = req.FolderID
+		}
+	}
+
+	// Save the link, recording a new version if its settings changed, and
+	// replace its tags if new ones were given
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveLinkVersion(tx, &link, previous, uintPtr(user.ID), nil); err != nil {
+			return err
+		}
+		if req.Tags != nil {
+			var tags []models.Tag
+			for _, name := range normalizeTagNames(req.Tags) {
+				tags = append(tags, models.Tag{Name: name, UserID: user.ID})
+			}
+			resolved, err := resolveTags(tx, tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&link).Association("Tags").Replace(resolved); err != nil {
+				return err
+			}
+			link.Tags = resolved
+		}
+
+		if err := recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkUpdate,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			Before:     before,
+			After:      linkAuditSnapshot(link),
+		}); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, user.ID, models.WebhookEventLinkUpdated, webhooks.NewLinkData(link))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update link",
+		})
+		return
+	}
+
+	invalidateLinkCaches(link.ID)
+
+	publishLinkEvent(user.ID, streamEventLinkUpdated, link.ID, toLinkResponse(link))
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
 		Success: true,
-		Data: dtos.LinkResponse{
-			ID:          link.ID,
-			ShortCode:   link.ShortCode,
-			OriginalURL: link.OriginalURL,
-			Clicks:      link.Clicks,
-			Favicon:     link.Favicon,
-			UserID:      link.UserID,
-			CreatedAt:   link.CreatedAt,
-		},
+		Data:    toLinkResponse(link),
 	})
 }
 
@@ -215,7 +537,7 @@ func DeleteLink(c *gin.Context) {
 
 	// Find the link
 	var link models.Link
-	if err := initializers.DB.First(&link, linkID).Error; err != nil {
+	if err := initializers.DB.Preload("Tags").First(&link, linkID).Error; err != nil {
 		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Link not found",
@@ -232,8 +554,23 @@ func DeleteLink(c *gin.Context) {
 		return
 	}
 
-	// Delete the link
-	if err := initializers.DB.Delete(&link).Error; err != nil {
+	// Move the link to the trash; it is purged after the retention period
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&link).Error; err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkDelete,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			Before:     linkAuditSnapshot(link),
+		}); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, user.ID, models.WebhookEventLinkDeleted, webhooks.NewLinkData(link))
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Failed to delete link",
@@ -241,12 +578,26 @@ func DeleteLink(c *gin.Context) {
 		return
 	}
 
+	invalidateLinkCaches(link.ID)
+
+	publishLinkEvent(user.ID, streamEventLinkDeleted, link.ID, gin.H{"id": link.ID})
+
 	c.JSON(http.StatusOK, dtos.SuccessResponse{
 		Success: true,
-		Message: "Link deleted successfully",
+		Message: "Link moved to trash",
 	})
 }
 
+// linkClicks selects the recorded clicks of a link, leaving visits from bots and
+// link previews out unless includeBots is set
+func linkClicks(linkID uint, includeBots bool) *gorm.DB {
+	db := initializers.DB.Model(&models.Click{}).Where("link_id = ?", linkID)
+	if !includeBots {
+		db = db.Where("traffic = ?", botfilter.Human)
+	}
+	return db
+}
+
 func GetLinkStats(c *gin.Context) {
 	// Get user from context
 	userInterface, exists := c.Get("user")
@@ -296,38 +647,56 @@ func GetLinkStats(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, dtos.SuccessResponse{
-		Success: true,
-		Data: dtos.LinkStatsResponse{
-			Clicks:      link.Clicks,
-			LastClicked: nil, // Could be enhanced with actual click tracking table
-		},
-	})
-}
+	var query dtos.LinkStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
 
-// RedirectLink handles public short link redirects (no auth required)
-func RedirectLink(c *gin.Context) {
-	shortCode := c.Param("shortCode")
+	variantStats, err := linkVariantStats(link.ID, query.IncludeBots)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch variant stats",
+		})
+		return
+	}
 
-	// Find the link by short code
-	var link models.Link
-	if err := initializers.DB.Where("short_code = ?", shortCode).First(&link).Error; err != nil {
-		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+	utmStats, err := linkUTMStats(link.ID, query.IncludeBots)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
 			Success: false,
-			Error:   "Link not found",
+			Error:   "Failed to fetch UTM stats",
+		})
+		return
+	}
+
+	versionStats, err := linkVersionStats(link.ID, query.IncludeBots)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch version stats",
 		})
 		return
 	}
 
-	// Increment click count
-	initializers.DB.Model(&link).Update("clicks", link.Clicks+1)
+	clicks := link.Clicks
+	if query.IncludeBots {
+		clicks += link.BotClicks
+	}
 
-	// Return the original URL for redirect
 	c.JSON(http.StatusOK, dtos.SuccessResponse{
 		Success: true,
-		Data: gin.H{
-			"originalUrl": link.OriginalURL,
+		Data: dtos.LinkStatsResponse{
+			Clicks:      clicks,
+			BotClicks:   link.BotClicks,
+			LastClicked: link.LastClickedAt,
+			Variants:    variantStats,
+			UTM:         utmStats,
+			Versions:    versionStats,
 		},
 	})
 }
-
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const exportBatchSize = 500
+
+// exportCSVHeader lists the exported columns; it can be fed back into ImportLinks
+var exportCSVHeader = []string{"id", "short_code", "destination", "clicks", "tags", "expires_at", "created_at", "last_clicked_at"}
+
+// formatExportTime formats an optional timestamp for CSV export
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func ExportLinks(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var query dtos.ExportLinksQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+	if query.Format == "" {
+		query.Format = "csv"
+	}
+
+	filename := "blinky-links-" + time.Now().UTC().Format("20060102") + "." + query.Format
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	// Links are streamed batch by batch so memory use doesn't grow with the number of links
+	links := initializers.DB.Preload("Tags").Where("user_id = ?", user.ID)
+	var batch []models.Link
+	var err error
+
+	if query.Format == "json" {
+		c.Header("Content-Type", "application/json")
+		c.Status(http.StatusOK)
+
+		encoder := json.NewEncoder(c.Writer)
+		first := true
+		c.Writer.WriteString("[")
+		err = links.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, link := range batch {
+				if !first {
+					c.Writer.WriteString(",")
+				}
+				first = false
+				if err := encoder.Encode(toLinkResponse(link)); err != nil {
+					return err
+				}
+			}
+			c.Writer.Flush()
+			return nil
+		}).Error
+		c.Writer.WriteString("]")
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+
+		writer := csv.NewWriter(c.Writer)
+		writer.Write(exportCSVHeader)
+		err = links.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, link := range batch {
+				writer.Write([]string{
+					strconv.FormatUint(uint64(link.ID), 10),
+					link.ShortCode,
+					link.OriginalURL,
+					strconv.Itoa(link.Clicks),
+					strings.Join(tagNames(link.Tags), "|"),
+					formatExportTime(link.ActiveUntil),
+					link.CreatedAt.UTC().Format(time.RFC3339),
+					formatExportTime(link.LastClickedAt),
+				})
+			}
+			writer.Flush()
+			c.Writer.Flush()
+			return writer.Error()
+		}).Error
+		writer.Flush()
+	}
+
+	// Headers are already sent at this point, so a failure can only be logged
+	if err != nil {
+		log.Printf("Failed to export links for user %d: %v", user.ID, err)
+	}
+}
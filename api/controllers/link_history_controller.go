@@ -0,0 +1,445 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/webhooks"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// historyCursor is the position encoded in link history cursors
+type historyCursor struct {
+	Version int `json:"v"`
+}
+
+// linkVersionOf snapshots the versioned fields of link
+func linkVersionOf(link models.Link) models.LinkVersion {
+	return models.LinkVersion{
+		LinkID:           link.ID,
+		Version:          link.Version,
+		OriginalURL:      link.OriginalURL,
+		ActiveFrom:       link.ActiveFrom,
+		ActiveUntil:      link.ActiveUntil,
+		FallbackURL:      link.FallbackURL,
+		StickyRotation:   link.StickyRotation,
+		QueryPassthrough: link.QueryPassthrough,
+		QueryPrecedence:  link.QueryPrecedence,
+		UTMSource:        link.UTMSource,
+		UTMMedium:        link.UTMMedium,
+		UTMCampaign:      link.UTMCampaign,
+		UTMTerm:          link.UTMTerm,
+		UTMContent:       link.UTMContent,
+	}
+}
+
+// sameTime reports whether two optional timestamps are the same instant
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// sameLinkSettings reports whether two snapshots have the same versioned fields
+func sameLinkSettings(a, b models.LinkVersion) bool {
+	return a.OriginalURL == b.OriginalURL &&
+		sameTime(a.ActiveFrom, b.ActiveFrom) &&
+		sameTime(a.ActiveUntil, b.ActiveUntil) &&
+		a.FallbackURL == b.FallbackURL &&
+		a.StickyRotation == b.StickyRotation &&
+		a.QueryPassthrough == b.QueryPassthrough &&
+		a.QueryPrecedence == b.QueryPrecedence &&
+		a.UTMSource == b.UTMSource &&
+		a.UTMMedium == b.UTMMedium &&
+		a.UTMCampaign == b.UTMCampaign &&
+		a.UTMTerm == b.UTMTerm &&
+		a.UTMContent == b.UTMContent
+}
+
+// appendLinkVersion bumps link's version and records its current settings in the
+// history. Links created before histories were kept first get their previous
+// settings recorded as version 1, with no author. The caller saves link.
+func appendLinkVersion(tx *gorm.DB, link *models.Link, previous models.Link, authorID *uint, restoredFrom *int) error {
+	if link.Version == 0 {
+		baseline := linkVersionOf(previous)
+		baseline.Version = 1
+		if err := tx.Create(&baseline).Error; err != nil {
+			return err
+		}
+		link.Version = 1
+	}
+
+	link.Version++
+	version := linkVersionOf(*link)
+	version.AuthorID = authorID
+	version.RestoredFrom = restoredFrom
+	return tx.Create(&version).Error
+}
+
+// linkSettingsColumns are the columns owners change by editing a link or restoring
+// one of its versions. Counters, moderation and the background jobs' columns are
+// left out so those edits can't overwrite what changed in the meantime.
+var linkSettingsColumns = []string{
+	"original_url", "active_from", "active_until", "fallback_url", "sticky_rotation",
+	"query_passthrough", "query_precedence",
+	"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+	"folder_id", "version", "expired_event_at",
+}
+
+// destinationColumns are reset along with a new destination by setDestination
+var destinationColumns = []string{
+	"title", "description", "image_url", "favicon_id", "metadata_fetched_at",
+	"health_checked_at", "health_next_check_at", "health_status_code", "health_latency_ms",
+	"health_redirect_chain", "health_error", "health_failures", "broken_at",
+}
+
+// saveLinkVersion saves a link changed from previous by authorID, adding an entry
+// to its history if any versioned field changed
+func saveLinkVersion(tx *gorm.DB, link *models.Link, previous models.Link, authorID *uint, restoredFrom *int) error {
+	// A link whose expiry moved can expire, and be announced, again
+	if !sameTime(link.ActiveUntil, previous.ActiveUntil) {
+		link.ExpiredEventAt = nil
+	}
+	if !sameLinkSettings(linkVersionOf(*link), linkVersionOf(previous)) {
+		if err := appendLinkVersion(tx, link, previous, authorID, restoredFrom); err != nil {
+			return err
+		}
+	}
+
+	columns := linkSettingsColumns
+	if link.OriginalURL != previous.OriginalURL {
+		columns = append(append([]string{}, columns...), destinationColumns...)
+	}
+	return tx.Model(link).Select(columns).Updates(link).Error
+}
+
+// linkVersionStats counts the clicks served under each version of a link
+func linkVersionStats(linkID uint, includeBots bool) ([]dtos.VersionStatsResponse, error) {
+	var counts []struct {
+		LinkVersion int
+		Clicks      int64
+	}
+	if err := linkClicks(linkID, includeBots).
+		Select("link_version, COUNT(*) AS clicks").
+		Group("link_version").
+		Order("link_version").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	var versions []models.LinkVersion
+	if err := initializers.DB.Select("version", "original_url").Where("link_id = ?", linkID).Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	destinations := map[int]string{}
+	for _, version := range versions {
+		destinations[version.Version] = version.OriginalURL
+	}
+
+	stats := make([]dtos.VersionStatsResponse, 0, len(counts))
+	for _, count := range counts {
+		stats = append(stats, dtos.VersionStatsResponse{
+			Version:     count.LinkVersion,
+			OriginalURL: destinations[count.LinkVersion],
+			Clicks:      count.Clicks,
+		})
+	}
+	return stats, nil
+}
+
+func toLinkVersionResponse(version models.LinkVersion, current int) dtos.LinkVersionResponse {
+	response := dtos.LinkVersionResponse{
+		Version:          version.Version,
+		Current:          version.Version == current,
+		AuthorID:         version.AuthorID,
+		RestoredFrom:     version.RestoredFrom,
+		OriginalURL:      version.OriginalURL,
+		ActiveFrom:       version.ActiveFrom,
+		ActiveUntil:      version.ActiveUntil,
+		FallbackURL:      version.FallbackURL,
+		StickyRotation:   version.StickyRotation,
+		QueryPassthrough: version.QueryPassthrough,
+		QueryPrecedence:  version.QueryPrecedence,
+		UTMParams: dtos.UTMParams{
+			UTMSource:   version.UTMSource,
+			UTMMedium:   version.UTMMedium,
+			UTMCampaign: version.UTMCampaign,
+			UTMTerm:     version.UTMTerm,
+			UTMContent:  version.UTMContent,
+		},
+		CreatedAt: version.CreatedAt,
+	}
+	if version.Author != nil {
+		response.AuthorName = version.Author.Name
+	}
+	return response
+}
+
+func GetLinkHistory(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Get link ID from URL param
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return
+	}
+
+	// Find the link
+	var link models.Link
+	if err := initializers.DB.First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return
+	}
+
+	// Check ownership
+	if link.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You don't have permission to view this link's history",
+		})
+		return
+	}
+
+	var query dtos.LinkHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	db := initializers.DB.Preload("Author").Where("link_id = ?", link.ID)
+
+	// Resume after the cursor position (newest first)
+	if query.Cursor != "" {
+		var cursor historyCursor
+		if err := decodeCursor(query.Cursor, &cursor); err != nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Invalid cursor",
+			})
+			return
+		}
+		db = db.Where("version < ?", cursor.Version)
+	}
+
+	// Fetch one extra row to know whether there is another page
+	limit := pageLimit(query.Limit)
+	var versions []models.LinkVersion
+	if err := db.Order("version DESC").Limit(limit + 1).Find(&versions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch link history",
+		})
+		return
+	}
+
+	paging := dtos.PageInfo{Limit: limit}
+	if len(versions) > limit {
+		versions = versions[:limit]
+		paging.HasMore = true
+		paging.NextCursor = encodeCursor(historyCursor{Version: versions[len(versions)-1].Version})
+	}
+
+	versionResponses := make([]dtos.LinkVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		versionResponses = append(versionResponses, toLinkVersionResponse(version, link.Version))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    versionResponses,
+		Paging:  &paging,
+	})
+}
+
+// RestoreLinkVersion puts a link's destination and settings back to how they were
+// in an earlier version. The rollback is itself recorded as a new version.
+func RestoreLinkVersion(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Get link ID and version from URL params
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return
+	}
+	versionNumber, err := strconv.Atoi(c.Param("version"))
+	if err != nil || versionNumber < 1 {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid version",
+		})
+		return
+	}
+
+	// Find the link
+	var link models.Link
+	if err := initializers.DB.Preload("Tags").First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return
+	}
+
+	// Check ownership
+	if link.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You don't have permission to update this link",
+		})
+		return
+	}
+
+	if rejectFlaggedUser(c, user) {
+		return
+	}
+
+	var version models.LinkVersion
+	if err := initializers.DB.Where("link_id = ? AND version = ?", link.ID, versionNumber).First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Version not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to fetch version",
+			})
+		}
+		return
+	}
+	if version.Version == link.Version {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Version is already current",
+		})
+		return
+	}
+
+	// Old destinations are screened again in case they have become unsafe since
+	if err := screenURL(version.OriginalURL); err != nil {
+		c.JSON(err.Status, dtos.ErrorResponse{
+			Success: false,
+			Error:   err.Message,
+		})
+		return
+	}
+	if version.FallbackURL != "" {
+		if err := screenURL(version.FallbackURL); err != nil {
+			c.JSON(err.Status, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Fallback " + err.Message,
+			})
+			return
+		}
+	}
+
+	previous := link
+	before := linkAuditSnapshot(link)
+
+	if version.OriginalURL != link.OriginalURL {
+		setDestination(&link, version.OriginalURL)
+	}
+	link.ActiveFrom = version.ActiveFrom
+	link.ActiveUntil = version.ActiveUntil
+	link.FallbackURL = version.FallbackURL
+	link.StickyRotation = version.StickyRotation
+	link.QueryPassthrough = version.QueryPassthrough
+	link.QueryPrecedence = version.QueryPrecedence
+	link.UTMSource = version.UTMSource
+	link.UTMMedium = version.UTMMedium
+	link.UTMCampaign = version.UTMCampaign
+	link.UTMTerm = version.UTMTerm
+	link.UTMContent = version.UTMContent
+
+	// Nothing is recorded if the restored settings match the current ones
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveLinkVersion(tx, &link, previous, uintPtr(user.ID), &versionNumber); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkRollback,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			Before:     before,
+			After:      linkAuditSnapshot(link),
+			Metadata:   map[string]interface{}{"restoredVersion": versionNumber, "version": link.Version},
+		}); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, user.ID, models.WebhookEventLinkUpdated, webhooks.NewLinkData(link))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to restore version",
+		})
+		return
+	}
+
+	invalidateLinkCaches(link.ID)
+
+	publishLinkEvent(user.ID, streamEventLinkUpdated, link.ID, toLinkResponse(link))
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(link),
+		Message: "Link restored to version " + strconv.Itoa(versionNumber),
+	})
+}
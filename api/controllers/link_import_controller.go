@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+const (
+	maxImportBytes = 5 << 20
+	maxImportRows  = 5000
+)
+
+// importColumns maps normalized CSV header names to the fields they fill
+var importColumns = map[string]string{
+	"destination": "destination",
+	"originalurl": "destination",
+	"url":         "destination",
+	"customcode":  "customCode",
+	"shortcode":   "customCode",
+	"code":        "customCode",
+	"tags":        "tags",
+	"expiresat":   "expiresAt",
+	"expiry":      "expiresAt",
+	"activeuntil": "expiresAt",
+}
+
+// importTimeLayouts are the accepted formats of the expiry column
+var importTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// normalizeHeader lowercases a header cell and strips separators so that
+// "Custom Code", "custom_code" and "customCode" are treated alike
+func normalizeHeader(name string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// splitTags splits a tags cell on the usual list separators
+func splitTags(cell string) []string {
+	return strings.FieldsFunc(cell, func(r rune) bool {
+		return r == '|' || r == ',' || r == ';'
+	})
+}
+
+// parseImportTime parses an expiry cell in any of the accepted layouts
+func parseImportTime(cell string) (time.Time, error) {
+	for _, layout := range importTimeLayouts {
+		if t, err := time.Parse(layout, cell); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid expiry, use RFC 3339 or YYYY-MM-DD")
+}
+
+// importSource returns the CSV to import, either from the "file" form field or the raw body
+func importSource(c *gin.Context) (io.ReadCloser, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		return header.Open()
+	}
+	return c.Request.Body, nil
+}
+
+func ImportLinks(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	if rejectFlaggedUser(c, user) {
+		return
+	}
+
+	var query dtos.ImportLinksQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	source, err := importSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to read CSV: " + err.Error(),
+		})
+		return
+	}
+	defer source.Close()
+
+	reader := csv.NewReader(source)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// Map the header row to known columns
+	header, err := reader.Read()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to read CSV header",
+		})
+		return
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		if field, known := importColumns[normalizeHeader(name)]; known {
+			if _, duplicate := columns[field]; !duplicate {
+				columns[field] = i
+			}
+		}
+	}
+	if _, found := columns["destination"]; !found {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "CSV header must contain a destination column",
+		})
+		return
+	}
+
+	cell := func(record []string, field string) string {
+		i, found := columns[field]
+		if !found || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	// Validate every row, reserving short codes within the file
+	var rows []dtos.ImportRowResult
+	var links []*models.Link
+	var generated []bool // whether each link's short code was generated
+	reserved := map[string]bool{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(rows) == maxImportRows {
+			c.JSON(http.StatusRequestEntityTooLarge, dtos.ErrorResponse{
+				Success: false,
+				Error:   "CSV has too many rows",
+			})
+			return
+		}
+
+		row := dtos.ImportRowResult{Row: line}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.JSON(http.StatusRequestEntityTooLarge, dtos.ErrorResponse{
+					Success: false,
+					Error:   "CSV file is too large",
+				})
+				return
+			}
+			row.Errors = append(row.Errors, "Malformed CSV row: "+err.Error())
+			rows = append(rows, row)
+			links = append(links, nil)
+			generated = append(generated, false)
+			continue
+		}
+
+		item := dtos.CreateLinkRequest{
+			OriginalURL: cell(record, "destination"),
+			CustomCode:  cell(record, "customCode"),
+			Tags:        splitTags(cell(record, "tags")),
+		}
+		// Exports hold links that have already expired; they are imported as
+		// expired rather than rejected like a past expiry on a new link
+		var pastExpiry *time.Time
+		if expiry := cell(record, "expiresAt"); expiry != "" {
+			t, err := parseImportTime(expiry)
+			if err != nil {
+				row.Errors = append(row.Errors, err.Error())
+			} else if t.After(time.Now()) {
+				item.ActiveUntil = &t
+			} else {
+				pastExpiry = &t
+			}
+		}
+		if err := binding.Validator.ValidateStruct(&item); err != nil {
+			row.Errors = append(row.Errors, "Invalid input: "+err.Error())
+		}
+
+		var link *models.Link
+		if len(row.Errors) == 0 {
+			built, linkErr := buildLink(initializers.DB, user.ID, item, reserved)
+			if linkErr != nil {
+				row.Errors = append(row.Errors, linkErr.Message)
+			} else {
+				if pastExpiry != nil {
+					// No link.expired event: the link expired before it got here
+					now := time.Now()
+					built.ActiveUntil = pastExpiry
+					built.ExpiredEventAt = &now
+				}
+				link = &built
+			}
+		}
+
+		rows = append(rows, row)
+		links = append(links, link)
+		generated = append(generated, item.CustomCode == "")
+	}
+
+	response := dtos.ImportLinksResponse{DryRun: query.DryRun, Total: len(rows)}
+	for i, link := range links {
+		if link == nil {
+			response.Invalid++
+			continue
+		}
+
+		// A dry run only previews what would be created. Otherwise a row is
+		// only valid once its link has been saved.
+		if !query.DryRun {
+			err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+				if err := createLink(tx, link, generated[i]); err != nil {
+					return err
+				}
+				if err := recordAuditEvent(tx, c, auditEntry{
+					Action:     models.AuditActionLinkCreate,
+					ActorID:    uintPtr(user.ID),
+					TargetType: models.AuditTargetLink,
+					TargetID:   uintPtr(link.ID),
+					After:      linkAuditSnapshot(*link),
+					Metadata:   map[string]interface{}{"import": true},
+				}); err != nil {
+					return err
+				}
+				return enqueueWebhookEvent(tx, user.ID, models.WebhookEventLinkCreated, webhooks.NewLinkData(*link))
+			})
+			if errors.Is(err, errShortCodeTaken) {
+				rows[i].Errors = append(rows[i].Errors, "Short code already exists")
+				response.Invalid++
+				continue
+			}
+			if err != nil {
+				rows[i].Errors = append(rows[i].Errors, "Failed to create link")
+				response.Invalid++
+				continue
+			}
+			response.Imported++
+
+			publishLinkEvent(user.ID, streamEventLinkCreated, link.ID, toLinkResponse(*link))
+		}
+
+		response.Valid++
+		preview := toLinkResponse(*link)
+		rows[i].Success = true
+		rows[i].Link = &preview
+	}
+	response.Rows = rows
+	if response.Rows == nil {
+		response.Rows = []dtos.ImportRowResult{}
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    response,
+	})
+}
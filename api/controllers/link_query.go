@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/models"
+	"gorm.io/gorm"
+)
+
+// destinationHostExpr extracts the host part of a link's destination URL
+const destinationHostExpr = `LOWER(substring(links.original_url from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/:?#]+)'))`
+
+// linkSortColumns maps the sort query param to the column expression used for ordering.
+// Never clicked links sort as clicked at the Unix epoch, the instant their cursors
+// carry; a bare date literal would depend on the session time zone.
+var linkSortColumns = map[string]string{
+	"created":     "links.created_at",
+	"clicks":      "links.clicks",
+	"lastClicked": "COALESCE(links.last_clicked_at, 'epoch'::timestamptz)",
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// linkCursor is the keyset position encoded in link list cursors
+type linkCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// likePattern escapes LIKE wildcards in term and wraps it for a substring match
+func likePattern(term string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(term) + "%"
+}
+
+// applyLinkFilters narrows db down to the links matching query
+func applyLinkFilters(db *gorm.DB, query dtos.LinkQuery) *gorm.DB {
+	// Every search term has to match either the short code or the destination
+	for _, term := range strings.Fields(query.Search) {
+		pattern := likePattern(term)
+		db = db.Where("(links.short_code ILIKE ? OR links.original_url ILIKE ?)", pattern, pattern)
+	}
+
+	// Domain matches the destination host and its subdomains
+	if domain := strings.ToLower(strings.TrimSpace(query.Domain)); domain != "" {
+		db = db.Where("("+destinationHostExpr+" = ? OR "+destinationHostExpr+" LIKE ?)", domain, "%."+domain)
+	}
+
+	// A domainId of 0 selects links on the default short domain
+	if query.DomainID != nil {
+		if *query.DomainID == 0 {
+			db = db.Where("links.domain_id IS NULL")
+		} else {
+			db = db.Where("links.domain_id = ?", *query.DomainID)
+		}
+	}
+
+	// Status is computed from the activation window
+	now := time.Now()
+	switch query.Status {
+	case models.LinkStatusScheduled:
+		db = db.Where("links.active_from > ?", now)
+	case models.LinkStatusActive:
+		db = db.Where("(links.active_from IS NULL OR links.active_from <= ?) AND (links.active_until IS NULL OR links.active_until > ?)", now, now)
+	case models.LinkStatusEnded:
+		db = db.Where("links.active_until <= ?", now)
+	}
+
+	// Broken links are those whose destination failed its health checks
+	if query.Broken != nil {
+		if *query.Broken {
+			db = db.Where("links.broken_at IS NOT NULL")
+		} else {
+			db = db.Where("links.broken_at IS NULL")
+		}
+	}
+
+	// Links must carry every requested tag
+	for _, tag := range normalizeTagNames(query.Tags) {
+		db = db.Where(`EXISTS (SELECT 1 FROM link_tags JOIN tags ON tags.id = link_tags.tag_id
+			WHERE link_tags.link_id = links.id AND tags.name = ?)`, tag)
+	}
+
+	// A folderId of 0 selects links outside any folder
+	if query.FolderID != nil {
+		switch {
+		case *query.FolderID == 0:
+			db = db.Where("links.folder_id IS NULL")
+		case query.Recursive:
+			db = db.Where(`links.folder_id IN (WITH RECURSIVE subtree AS (
+				SELECT id FROM folders WHERE id = ?
+				UNION ALL
+				SELECT folders.id FROM folders JOIN subtree ON folders.parent_id = subtree.id
+			) SELECT id FROM subtree)`, *query.FolderID)
+		default:
+			db = db.Where("links.folder_id = ?", *query.FolderID)
+		}
+	}
+
+	if !query.CreatedFrom.IsZero() {
+		db = db.Where("links.created_at >= ?", query.CreatedFrom)
+	}
+	if !query.CreatedTo.IsZero() {
+		db = db.Where("links.created_at < ?", query.CreatedTo)
+	}
+
+	return db
+}
+
+// linkSortValue returns the cursor value of link for the given sort
+func linkSortValue(link models.Link, sort string) string {
+	switch sort {
+	case "clicks":
+		return strconv.Itoa(link.Clicks)
+	case "lastClicked":
+		if link.LastClickedAt == nil {
+			return time.Unix(0, 0).UTC().Format(time.RFC3339Nano)
+		}
+		return link.LastClickedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		return link.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// applyLinkOrder orders db by the requested sort and resumes after cursor, if any
+func applyLinkOrder(db *gorm.DB, sort, order, cursor string) (*gorm.DB, error) {
+	column := linkSortColumns[sort]
+	direction, comparison := "DESC", "<"
+	if order == "asc" {
+		direction, comparison = "ASC", ">"
+	}
+
+	if cursor != "" {
+		var position linkCursor
+		if err := decodeCursor(cursor, &position); err != nil {
+			return nil, errInvalidCursor
+		}
+		if position.Sort != sort || position.Order != order {
+			return nil, errInvalidCursor
+		}
+
+		var value interface{}
+		if sort == "clicks" {
+			clicks, err := strconv.Atoi(position.Value)
+			if err != nil {
+				return nil, errInvalidCursor
+			}
+			value = clicks
+		} else {
+			t, err := time.Parse(time.RFC3339Nano, position.Value)
+			if err != nil {
+				return nil, errInvalidCursor
+			}
+			value = t
+		}
+
+		db = db.Where("("+column+", links.id) "+comparison+" (?, ?)", value, position.ID)
+	}
+
+	return db.Order(column + " " + direction).Order("links.id " + direction), nil
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/jobs"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/webhooks"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// trashCursor is the keyset position encoded in trash list cursors
+type trashCursor struct {
+	DeletedAt time.Time `json:"d"`
+	ID        uint      `json:"id"`
+}
+
+func GetTrashedLinks(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var query dtos.TrashQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	db := initializers.DB.Unscoped().Preload("Tags").
+		Where("user_id = ? AND deleted_at IS NOT NULL", user.ID)
+
+	// Resume after the cursor position (most recently deleted first)
+	if query.Cursor != "" {
+		var cursor trashCursor
+		if err := decodeCursor(query.Cursor, &cursor); err != nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Invalid cursor",
+			})
+			return
+		}
+		db = db.Where("(deleted_at, id) < (?, ?)", cursor.DeletedAt, cursor.ID)
+	}
+
+	// Fetch one extra row to know whether there is another page
+	limit := pageLimit(query.Limit)
+	var links []models.Link
+	if err := db.Order("deleted_at DESC").Order("id DESC").Limit(limit + 1).Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch trash",
+		})
+		return
+	}
+
+	paging := dtos.PageInfo{Limit: limit}
+	if len(links) > limit {
+		links = links[:limit]
+		last := links[len(links)-1]
+		paging.HasMore = true
+		paging.NextCursor = encodeCursor(trashCursor{DeletedAt: last.DeletedAt.Time, ID: last.ID})
+	}
+
+	retention := jobs.TrashRetention()
+	linkResponses := make([]dtos.TrashedLinkResponse, 0, len(links))
+	for _, link := range links {
+		linkResponses = append(linkResponses, dtos.TrashedLinkResponse{
+			LinkResponse: toLinkResponse(link),
+			DeletedAt:    link.DeletedAt.Time,
+			PurgeAt:      link.DeletedAt.Time.Add(retention),
+		})
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    linkResponses,
+		Paging:  &paging,
+	})
+}
+
+func RestoreLink(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Get link ID from URL param
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return
+	}
+
+	// Find the trashed link
+	var link models.Link
+	if err := initializers.DB.Unscoped().Preload("Tags").Where("deleted_at IS NOT NULL").First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found in trash",
+		})
+		return
+	}
+
+	// Check ownership
+	if link.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You don't have permission to restore this link",
+		})
+		return
+	}
+
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&link).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		link.DeletedAt = gorm.DeletedAt{}
+		if err := recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkRestore,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			After:      linkAuditSnapshot(link),
+		}); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, user.ID, models.WebhookEventLinkRestored, webhooks.NewLinkData(link))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to restore link",
+		})
+		return
+	}
+
+	publishLinkEvent(user.ID, streamEventLinkRestored, link.ID, toLinkResponse(link))
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toLinkResponse(link),
+		Message: "Link restored successfully",
+	})
+}
+
+// PurgeLink permanently deletes a link, whether it is in the trash or not,
+// and frees its short code
+func PurgeLink(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Get link ID from URL param
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return
+	}
+
+	// Find the link, including trashed ones
+	var link models.Link
+	if err := initializers.DB.Unscoped().Preload("Tags").First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return
+	}
+
+	// Check ownership
+	if link.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You don't have permission to delete this link",
+		})
+		return
+	}
+
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&link).Error; err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkPurge,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			Before:     linkAuditSnapshot(link),
+		}); err != nil {
+			return err
+		}
+
+		// Trashed links were announced as deleted when they were moved to the trash
+		if link.DeletedAt.Valid {
+			return nil
+		}
+		return enqueueWebhookEvent(tx, user.ID, models.WebhookEventLinkDeleted, webhooks.NewLinkData(link))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete link",
+		})
+		return
+	}
+
+	invalidateLinkCaches(link.ID)
+
+	if !link.DeletedAt.Valid {
+		publishLinkEvent(user.ID, streamEventLinkDeleted, link.ID, gin.H{"id": link.ID})
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Message: "Link permanently deleted",
+	})
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+)
+
+// notificationCursor is the position encoded in notification list cursors
+type notificationCursor struct {
+	ID uint `json:"id"`
+}
+
+func toNotificationResponse(notification models.Notification) dtos.NotificationResponse {
+	return dtos.NotificationResponse{
+		ID:        notification.ID,
+		Kind:      notification.Kind,
+		Message:   notification.Message,
+		LinkID:    notification.LinkID,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+func GetNotifications(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var query dtos.NotificationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	db := initializers.DB.Where("user_id = ?", user.ID)
+	if query.Unread {
+		db = db.Where("read_at IS NULL")
+	}
+
+	// Resume after the cursor position (newest first)
+	if query.Cursor != "" {
+		var cursor notificationCursor
+		if err := decodeCursor(query.Cursor, &cursor); err != nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Invalid cursor",
+			})
+			return
+		}
+		db = db.Where("id < ?", cursor.ID)
+	}
+
+	// Fetch one extra row to know whether there is another page
+	limit := pageLimit(query.Limit)
+	var notifications []models.Notification
+	if err := db.Order("id DESC").Limit(limit + 1).Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch notifications",
+		})
+		return
+	}
+
+	paging := dtos.PageInfo{Limit: limit}
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		paging.HasMore = true
+		paging.NextCursor = encodeCursor(notificationCursor{ID: notifications[len(notifications)-1].ID})
+	}
+
+	notificationResponses := make([]dtos.NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		notificationResponses = append(notificationResponses, toNotificationResponse(notification))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    notificationResponses,
+		Paging:  &paging,
+	})
+}
+
+func MarkNotificationRead(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Get notification ID from URL param
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid notification ID",
+		})
+		return
+	}
+
+	var notification models.Notification
+	if err := initializers.DB.Where("user_id = ?", user.ID).First(&notification, notificationID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Notification not found",
+		})
+		return
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := initializers.DB.Model(&notification).Update("read_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to update notification",
+			})
+			return
+		}
+		notification.ReadAt = &now
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toNotificationResponse(notification),
+	})
+}
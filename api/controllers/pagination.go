@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// pageLimit clamps a requested page size to the allowed range
+func pageLimit(requested int) int {
+	if requested <= 0 {
+		return defaultPageLimit
+	}
+	if requested > maxPageLimit {
+		return maxPageLimit
+	}
+	return requested
+}
+
+// encodeCursor serializes the position of the last returned row into an opaque cursor
+func encodeCursor(position interface{}) string {
+	raw, err := json.Marshal(position)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor parses a cursor produced by encodeCursor into position
+func decodeCursor(cursor string, position interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, position)
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/qr"
+	"github.com/gin-gonic/gin"
+)
+
+const qrCacheCapacity = 256
+
+var qrCache = qr.NewCache(qrCacheCapacity)
+
+var (
+	qrLogoOnce sync.Once
+	qrLogo     image.Image
+)
+
+// loadQRLogo returns the logo configured with QR_LOGO_PATH, or nil if there is none
+func loadQRLogo() image.Image {
+	qrLogoOnce.Do(func() {
+		path := os.Getenv("QR_LOGO_PATH")
+		if path == "" {
+			return
+		}
+		logo, err := qr.LoadLogo(path)
+		if err != nil {
+			log.Printf("Failed to load QR logo %s: %v", path, err)
+			return
+		}
+		qrLogo = logo
+	})
+	return qrLogo
+}
+
+// withDefault returns value, or def when value is empty
+func withDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+func GetLinkQRCode(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Get link ID from URL param
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return
+	}
+
+	// Find the link
+	var link models.Link
+	if err := initializers.DB.Preload("Domain").First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return
+	}
+
+	// Check ownership
+	if link.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You don't have permission to view this link's QR code",
+		})
+		return
+	}
+
+	var query dtos.QRCodeQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// Fill in defaults and parse the rendering options
+	if query.Format == "" {
+		query.Format = "png"
+	}
+	opts := qr.Options{Size: 512, Level: "M", Margin: 4}
+	if query.Size != 0 {
+		opts.Size = query.Size
+	}
+	if query.Level != "" {
+		opts.Level, _ = qr.ParseLevel(query.Level)
+	}
+	if query.Margin != nil {
+		opts.Margin = *query.Margin
+	}
+	if opts.Foreground, err = qr.ParseColor(withDefault(query.Foreground, "000000")); err == nil {
+		opts.Background, err = qr.ParseColor(withDefault(query.Background, "ffffff"))
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+	if query.Logo {
+		opts.Logo = loadQRLogo()
+		if opts.Logo == nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "No QR code logo is configured",
+			})
+			return
+		}
+	}
+
+	content, err := shortLinkURL(link)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to build short URL",
+		})
+		return
+	}
+
+	// The key changes whenever the link or the options do
+	key := fmt.Sprintf("%d|%d|%s|%s|%d|%s|%d|%s|%s|%t", link.ID, link.UpdatedAt.UnixNano(), content,
+		query.Format, opts.Size, opts.Level, opts.Margin, qr.HexColor(opts.Foreground), qr.HexColor(opts.Background), query.Logo)
+	sum := sha1.Sum([]byte(key))
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	contentType := "image/png"
+	if query.Format == "svg" {
+		contentType = "image/svg+xml"
+	}
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("ETag", etag)
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	rendered, cached := qrCache.Get(key)
+	if !cached {
+		if query.Format == "svg" {
+			rendered, err = qr.RenderSVG(content, opts)
+		} else {
+			rendered, err = qr.RenderPNG(content, opts)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to render QR code",
+			})
+			return
+		}
+		qrCache.Put(link.ID, key, rendered)
+	}
+
+	c.Data(http.StatusOK, contentType, rendered)
+}
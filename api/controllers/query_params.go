@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"net/url"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/models"
+)
+
+// linkUTMParams returns the UTM parameters configured on a link
+func linkUTMParams(link models.Link) url.Values {
+	params := url.Values{}
+	for key, value := range map[string]string{
+		"utm_source":   link.UTMSource,
+		"utm_medium":   link.UTMMedium,
+		"utm_campaign": link.UTMCampaign,
+		"utm_term":     link.UTMTerm,
+		"utm_content":  link.UTMContent,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	return params
+}
+
+// applyQueryParams adds the link's UTM parameters to destination and, when the
+// link has query passthrough, the visitor's query. On conflicts the link's UTM
+// values beat the destination's, and the link's precedence decides between the
+// visitor's and the rest. The destination is returned untouched if nothing is added.
+func applyQueryParams(destination string, link models.Link, incoming url.Values) string {
+	utm := linkUTMParams(link)
+	if len(utm) == 0 && (!link.QueryPassthrough || len(incoming) == 0) {
+		return destination
+	}
+
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+
+	query := parsed.Query()
+	for key, values := range utm {
+		query[key] = values
+	}
+	if link.QueryPassthrough {
+		for key, values := range incoming {
+			_, conflict := query[key]
+			if !conflict || link.QueryPrecedence == models.QueryPrecedenceIncoming {
+				query[key] = values
+			}
+		}
+	}
+
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// destinationQuery returns the query parameters of a destination, e.g. to find
+// out which UTM parameters a visitor was finally sent with
+func destinationQuery(destination string) url.Values {
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return url.Values{}
+	}
+	return parsed.Query()
+}
+
+// linkUTMStats counts a link's clicks by each UTM parameter, leaving out clicks
+// without that parameter. It returns nil if no click had any.
+func linkUTMStats(linkID uint, includeBots bool) (*dtos.UTMStatsResponse, error) {
+	stats := &dtos.UTMStatsResponse{}
+	found := false
+	for _, breakdown := range []struct {
+		column string
+		counts *[]dtos.UTMValueCount
+	}{
+		{"utm_source", &stats.Sources},
+		{"utm_medium", &stats.Mediums},
+		{"utm_campaign", &stats.Campaigns},
+		{"utm_term", &stats.Terms},
+		{"utm_content", &stats.Contents},
+	} {
+		*breakdown.counts = []dtos.UTMValueCount{}
+		if err := linkClicks(linkID, includeBots).
+			Select(breakdown.column + " AS value, COUNT(*) AS clicks").
+			Where(breakdown.column + " <> ''").
+			Group(breakdown.column).
+			Order("clicks DESC").
+			Scan(breakdown.counts).Error; err != nil {
+			return nil, err
+		}
+		found = found || len(*breakdown.counts) > 0
+	}
+
+	if !found {
+		return nil, nil
+	}
+	return stats, nil
+}
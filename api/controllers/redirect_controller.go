@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/caiohportella/blinky/botfilter"
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/webhooks"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// findRedirectLink looks shortCode up on the custom domain serving host, or on the
+// default short domain when host isn't a verified custom domain
+func findRedirectLink(host, shortCode string) (models.Link, error) {
+	domain, err := verifiedDomainForHost(host)
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	// Codes are unique ignoring case, so /AbC and /abc are the same link. This
+	// matches the expression of idx_links_domain_lower_short_code.
+	var domainKey uint
+	if domain != nil {
+		domainKey = domain.ID
+	}
+
+	var link models.Link
+	err = initializers.DB.
+		Where("COALESCE(domain_id, 0) = ? AND LOWER(short_code) = LOWER(?)", domainKey, shortCode).
+		First(&link).Error
+	return link, err
+}
+
+// disabledInterstitial explains to visitors why a link no longer works
+func disabledInterstitial(link models.Link) dtos.InterstitialPayload {
+	message := "This link has been disabled by our moderators after it was reported for abuse."
+	if link.DisabledBy == models.LinkDisabledByScreening {
+		message = "This link has been disabled because its destination is considered unsafe."
+	}
+	return dtos.InterstitialPayload{
+		Title:     "Link disabled",
+		Message:   message,
+		Reason:    link.DisabledReason,
+		ShortCode: link.ShortCode,
+	}
+}
+
+// resolveRedirect works out where a visit to shortCode should go and records the
+// click. When the link can't be followed it writes the error response and returns false.
+func resolveRedirect(c *gin.Context, shortCode string) (string, bool) {
+	// Find the link by short code
+	link, err := findRedirectLink(c.Request.Host, shortCode)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Link not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to look up link",
+			})
+		}
+		return "", false
+	}
+
+	// Disabled links show an interstitial instead of sending visitors on
+	if link.DisabledAt != nil {
+		c.JSON(http.StatusUnavailableForLegalReasons, dtos.DisabledLinkResponse{
+			Success:      false,
+			Error:        "Link has been disabled",
+			Interstitial: disabledInterstitial(link),
+		})
+		return "", false
+	}
+
+	// Outside the activation window visitors get the fallback URL, if there is one
+	switch link.Status(time.Now()) {
+	case models.LinkStatusScheduled:
+		if link.FallbackURL != "" {
+			return link.FallbackURL, true
+		}
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link is not active yet",
+		})
+		return "", false
+	case models.LinkStatusEnded:
+		if link.FallbackURL != "" {
+			return link.FallbackURL, true
+		}
+		c.JSON(http.StatusGone, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link has expired",
+		})
+		return "", false
+	}
+
+	// The first matching targeting rule overrides the destination
+	var rules []models.TargetingRule
+	if err := initializers.DB.Where("link_id = ?", link.ID).Order("position").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to look up link",
+		})
+		return "", false
+	}
+	v := newVisitor(c)
+	click := models.Click{
+		LinkID:         link.ID,
+		LinkVersion:    link.Version,
+		Destination:    link.OriginalURL,
+		Country:        v.Country,
+		OS:             v.OS,
+		OSVersion:      v.OSVersion,
+		Browser:        v.Browser,
+		BrowserVersion: v.BrowserVersion,
+		Device:         v.Device,
+		Language:       v.Language,
+		Referrer:       c.Request.Referer(),
+		VisitorID:      v.ID,
+		Traffic:        initializers.BotClassifier.Classify(c.Request),
+	}
+	if rule := matchTargetingRule(rules, v); rule != nil {
+		click.TargetingRuleID = &rule.ID
+		click.Destination = rule.DestinationURL
+	} else {
+		// Visitors no rule claimed rotate between the link's variants
+		var variants []models.LinkVariant
+		if err := initializers.DB.Where("link_id = ?", link.ID).Order("id").Find(&variants).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Failed to look up link",
+			})
+			return "", false
+		}
+		if variant := pickVariant(c, link, variants); variant != nil {
+			click.VariantID = &variant.ID
+			click.Destination = variant.DestinationURL
+		}
+	}
+
+	// Add UTM parameters and pass the visitor's query through
+	click.Destination = applyQueryParams(click.Destination, link, c.Request.URL.Query())
+	query := destinationQuery(click.Destination)
+	click.UTMSource = query.Get("utm_source")
+	click.UTMMedium = query.Get("utm_medium")
+	click.UTMCampaign = query.Get("utm_campaign")
+	click.UTMTerm = query.Get("utm_term")
+	click.UTMContent = query.Get("utm_content")
+
+	// Bots and link previews are recorded but kept out of the click count
+	if click.Traffic != botfilter.Human {
+		initializers.DB.Model(&link).Update("bot_clicks", gorm.Expr("bot_clicks + 1"))
+		if err := initializers.DB.Create(&click).Error; err != nil {
+			log.Printf("Failed to record click on link %d: %v", link.ID, err)
+		}
+		return click.Destination, true
+	}
+
+	// Increment click count and remember when the link was last used
+	initializers.DB.Model(&link).Updates(map[string]interface{}{
+		"clicks":          gorm.Expr("clicks + 1"),
+		"last_clicked_at": time.Now(),
+	})
+	link.Clicks++
+
+	// Record the click and queue its webhook event together
+	var clicked webhooks.ClickedData
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&click).Error; err != nil {
+			return err
+		}
+		clicked = webhooks.ClickedData{
+			Link:  webhooks.NewLinkData(link),
+			Click: webhooks.NewClickData(click),
+		}
+		return enqueueWebhookEvent(tx, link.UserID, models.WebhookEventLinkClicked, clicked)
+	})
+	if err != nil {
+		log.Printf("Failed to record click on link %d: %v", link.ID, err)
+	} else {
+		publishLinkEvent(link.UserID, streamEventLinkClicked, link.ID, clicked)
+	}
+
+	return click.Destination, true
+}
+
+// RedirectLink handles public short link redirects (no auth required)
+func RedirectLink(c *gin.Context) {
+	destination, ok := resolveRedirect(c, c.Param("shortCode"))
+	if !ok {
+		return
+	}
+
+	// Return the original URL for redirect
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: gin.H{
+			"originalUrl": destination,
+		},
+	})
+}
+
+// RedirectByHost serves links on custom short domains, e.g. go.example.com/launch.
+// It is installed as the router's fallback, so anything else is a 404.
+func RedirectByHost(c *gin.Context) {
+	shortCode := strings.Trim(c.Request.URL.Path, "/")
+	isRedirectRequest := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+	if !isRedirectRequest || shortCode == "" || strings.Contains(shortCode, "/") {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Not found",
+		})
+		return
+	}
+
+	domain, err := verifiedDomainForHost(c.Request.Host)
+	if err != nil || domain == nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Not found",
+		})
+		return
+	}
+
+	destination, ok := resolveRedirect(c, shortCode)
+	if !ok {
+		return
+	}
+
+	c.Redirect(http.StatusFound, destination)
+}
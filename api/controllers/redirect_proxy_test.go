@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/caiohportella/blinky/botfilter"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/useragent"
+	"github.com/gin-gonic/gin"
+)
+
+const slackUserAgent = "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"
+
+// proxiedVisit describes what the redirect path sees of a request
+type proxiedVisit struct {
+	ClientIP string
+	Visitor  visitor
+	Traffic  string
+	Variant  uint
+}
+
+// serveProxied sends req through a router configured like main's and returns
+// what resolveRedirect would record about the visitor
+func serveProxied(t *testing.T, req *http.Request) proxiedVisit {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	initializers.BotClassifier = botfilter.NewClassifier()
+	initializers.UserAgentParser = useragent.NewParser()
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(initializers.TrustedProxies()); err != nil {
+		t.Fatal(err)
+	}
+
+	var visit proxiedVisit
+	router.GET("/r/:shortCode", func(c *gin.Context) {
+		visit.ClientIP = c.ClientIP()
+		visit.Visitor = newVisitor(c)
+		visit.Traffic = initializers.BotClassifier.Classify(c.Request)
+		link := models.Link{StickyRotation: models.StickyRotationCookie}
+		link.ID = 1
+		variants := []models.LinkVariant{{Weight: 1}, {Weight: 1}}
+		variants[0].ID, variants[1].ID = 10, 20
+		visit.Variant = pickVariant(c, link, variants).ID
+	})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	return visit
+}
+
+// newProxiedRequest builds the request the frontend sends on behalf of a visitor
+func newProxiedRequest(method, visitorIP, userAgent string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/r/launch", nil)
+	req.RemoteAddr = "127.0.0.1:52000"
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Forwarded-For", visitorIP)
+	req.Header.Set("X-Forwarded-Method", method)
+	return req
+}
+
+func TestProxiedRedirectKeepsVariantCookie(t *testing.T) {
+	req := newProxiedRequest(http.MethodGet, "203.0.113.7", "Mozilla/5.0 Chrome/120.0")
+	req.AddCookie(&http.Cookie{Name: variantCookiePrefix + "1", Value: "20"})
+	visit := serveProxied(t, req)
+	if visit.Variant != 20 {
+		t.Errorf("variant = %d, want the one in the forwarded cookie", visit.Variant)
+	}
+	// IP stickiness buckets visitors by this address, not the proxy's
+	if visit.ClientIP != "203.0.113.7" {
+		t.Errorf("client IP = %q, want the visitor's", visit.ClientIP)
+	}
+}
+
+func TestProxiedRedirectSeesVisitor(t *testing.T) {
+	ua := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1"
+	visit := serveProxied(t, newProxiedRequest(http.MethodGet, "203.0.113.7", ua))
+
+	if visit.ClientIP != "203.0.113.7" {
+		t.Errorf("client IP = %q, want the visitor's", visit.ClientIP)
+	}
+	if visit.Visitor.ID != visitorID("203.0.113.7", ua) {
+		t.Error("visitor ID not derived from the visitor's IP and User-Agent")
+	}
+	if visit.Visitor.OS != "ios" || visit.Visitor.Device != useragent.DeviceMobile || visit.Visitor.Browser != "safari" {
+		t.Errorf("agent = %+v, want safari on an iOS phone", visit.Visitor.Agent)
+	}
+	if visit.Traffic != botfilter.Human {
+		t.Errorf("traffic = %q, want human", visit.Traffic)
+	}
+
+	other := serveProxied(t, newProxiedRequest(http.MethodGet, "198.51.100.20", ua))
+	if other.Visitor.ID == visit.Visitor.ID {
+		t.Error("different visitors behind the same proxy share a visitor ID")
+	}
+}
+
+func TestProxiedRedirectClassifiesUnfurlers(t *testing.T) {
+	if traffic := serveProxied(t, newProxiedRequest(http.MethodGet, "203.0.113.7", slackUserAgent)).Traffic; traffic != botfilter.Preview {
+		t.Errorf("Slack unfurl classified %q, want preview", traffic)
+	}
+
+	chrome := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+	if traffic := serveProxied(t, newProxiedRequest(http.MethodHead, "203.0.113.7", chrome)).Traffic; traffic != botfilter.Bot {
+		t.Errorf("forwarded HEAD classified %q, want bot", traffic)
+	}
+}
+
+func TestUntrustedForwardedForIgnored(t *testing.T) {
+	req := newProxiedRequest(http.MethodGet, "203.0.113.7", "Mozilla/5.0 Chrome/120.0")
+	req.RemoteAddr = "192.0.2.50:41000"
+	if ip := serveProxied(t, req).ClientIP; ip != "192.0.2.50" {
+		t.Errorf("client IP = %q, want the untrusted peer's own address", ip)
+	}
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand/v2"
+	"os"
+	"strconv"
+
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	variantCookiePrefix = "blinky_v_"
+	variantCookieMaxAge = 30 * 24 * 60 * 60
+)
+
+// weightedVariant returns the variant that n falls into when the variants'
+// weights are laid end to end; n must be below their total weight
+func weightedVariant(variants []models.LinkVariant, n int) *models.LinkVariant {
+	for i := range variants {
+		if n < variants[i].Weight {
+			return &variants[i]
+		}
+		n -= variants[i].Weight
+	}
+	return &variants[len(variants)-1]
+}
+
+// visitorBucket deterministically maps a visitor's IP to a number in [0, total).
+// The IP is keyed with the server secret so buckets can't be worked out from outside.
+func visitorBucket(linkID uint, ip string, total int) int {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("SECRET_KEY")))
+	mac.Write([]byte(strconv.FormatUint(uint64(linkID), 10) + "|" + ip))
+	return int(binary.BigEndian.Uint64(mac.Sum(nil)[:8]) % uint64(total))
+}
+
+// pickVariant chooses which variant of link the visitor is sent to, keeping
+// them on the same one if the link is sticky. It returns nil without variants.
+func pickVariant(c *gin.Context, link models.Link, variants []models.LinkVariant) *models.LinkVariant {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+
+	switch link.StickyRotation {
+	case models.StickyRotationIP:
+		return weightedVariant(variants, visitorBucket(link.ID, c.ClientIP(), total))
+
+	case models.StickyRotationCookie:
+		cookieName := variantCookiePrefix + strconv.FormatUint(uint64(link.ID), 10)
+		if value, err := c.Cookie(cookieName); err == nil {
+			for i := range variants {
+				if strconv.FormatUint(uint64(variants[i].ID), 10) == value {
+					return &variants[i]
+				}
+			}
+		}
+		// New visitor, or their variant was deleted
+		variant := weightedVariant(variants, rand.IntN(total))
+		c.SetCookie(cookieName, strconv.FormatUint(uint64(variant.ID), 10), variantCookieMaxAge, "/", "", c.Request.TLS != nil, true)
+		return variant
+	}
+
+	return weightedVariant(variants, rand.IntN(total))
+}
@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/middlewares"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/pubsub"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// streamTokenLifetime is how long a stream token can be used to open a stream
+const streamTokenLifetime = time.Minute
+
+// Events pushed to link streams
+const (
+	streamEventLinkCreated  = "link.created"
+	streamEventLinkUpdated  = "link.updated"
+	streamEventLinkDeleted  = "link.deleted"
+	streamEventLinkRestored = "link.restored"
+	streamEventLinkClicked  = "link.clicked"
+)
+
+// publishLinkEvent pushes an event about a link to its owner's open streams
+func publishLinkEvent(userID uint, eventType string, linkID uint, data interface{}) {
+	initializers.EventHub.Publish(userID, pubsub.Event{Type: eventType, LinkID: linkID, Data: data})
+}
+
+// tokenRevoked reports whether user revoked their tokens, or was deleted, since
+// they were loaded. Lookup failures leave the stream open.
+func tokenRevoked(user models.User) bool {
+	var current models.User
+	err := initializers.DB.Select("token_version").Take(&current, user.ID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true
+	}
+	if err != nil {
+		log.Printf("Failed to check the tokens of user %d: %v", user.ID, err)
+		return false
+	}
+	return current.TokenVersion != user.TokenVersion
+}
+
+// streamEvents sends the user's events, only those about linkID if it isn't 0,
+// as Server-Sent Events until the client goes away, the server shuts down or
+// the user revokes their tokens
+func streamEvents(c *gin.Context, user models.User, linkID uint) {
+	sub := initializers.EventHub.Subscribe(user.ID, linkID)
+	if sub == nil {
+		c.JSON(http.StatusServiceUnavailable, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Server is shutting down",
+		})
+		return
+	}
+	defer initializers.EventHub.Unsubscribe(sub)
+
+	heartbeat := time.NewTicker(initializers.GetEnvDuration("STREAM_HEARTBEAT_INTERVAL", 15*time.Second))
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Tell the client how soon to reconnect if the stream drops
+	io.WriteString(c.Writer, "retry: 3000\n\n")
+	c.Writer.Flush()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			// The subscription ends when the client lagged too far behind or
+			// the server is shutting down; the client reconnects either way
+			if !ok {
+				return
+			}
+			c.SSEvent(event.Type, event.Data)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			// The token the stream was opened with may have been revoked since
+			if tokenRevoked(user) {
+				return
+			}
+
+			// Comments keep proxies from closing idle connections
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// StreamLinks pushes click and change events for all of the user's links
+func StreamLinks(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	streamEvents(c, user, 0)
+}
+
+// StreamLink pushes click and change events for one link
+func StreamLink(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Get link ID from URL param
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return
+	}
+
+	// Find the link
+	var link models.Link
+	if err := initializers.DB.First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return
+	}
+
+	// Check ownership
+	if link.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You don't have permission to view this link",
+		})
+		return
+	}
+
+	streamEvents(c, user, link.ID)
+}
+
+// CreateStreamToken issues a short-lived token that opens the user's event streams
+// as the token query parameter, for clients such as a browser's EventSource that
+// can't send an Authorization header
+func CreateStreamToken(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(streamTokenLifetime)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": user.ID,
+		"ver": user.TokenVersion,
+		"aud": middlewares.StreamTokenAudience,
+		"exp": expiresAt.Unix(),
+	}).SignedString([]byte(os.Getenv("SECRET_KEY")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create stream token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    dtos.StreamTokenResponse{Token: token, ExpiresAt: expiresAt},
+	})
+}
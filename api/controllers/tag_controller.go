@@ -0,0 +1,349 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// tagNameTaken reports whether the user already has another tag with this name
+func tagNameTaken(userID uint, name string, exceptID uint) (bool, error) {
+	var existing models.Tag
+	err := initializers.DB.Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID).First(&existing).Error
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// findUserTag loads a tag by the :id URL param and checks that it belongs to user,
+// writing the error response if it doesn't
+func findUserTag(c *gin.Context, user models.User) (models.Tag, bool) {
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid tag ID",
+		})
+		return models.Tag{}, false
+	}
+
+	var tag models.Tag
+	if err := initializers.DB.Where("user_id = ?", user.ID).First(&tag, tagID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Tag not found",
+		})
+		return models.Tag{}, false
+	}
+
+	return tag, true
+}
+
+func GetTags(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Fetch tags with the number of live links using them
+	var tags []dtos.TagResponse
+	err := initializers.DB.Model(&models.Tag{}).
+		Select("tags.id, tags.name, tags.created_at, COUNT(links.id) AS link_count").
+		Joins("LEFT JOIN link_tags ON link_tags.tag_id = tags.id").
+		Joins("LEFT JOIN links ON links.id = link_tags.link_id AND links.deleted_at IS NULL").
+		Where("tags.user_id = ?", user.ID).
+		Group("tags.id").
+		Order("tags.name").
+		Scan(&tags).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch tags",
+		})
+		return
+	}
+
+	if tags == nil {
+		tags = []dtos.TagResponse{}
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    tags,
+	})
+}
+
+func CreateTag(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var req dtos.TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	taken, err := tagNameTaken(user.ID, name, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check tag name",
+		})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Tag already exists",
+		})
+		return
+	}
+
+	tag := models.Tag{Name: name, UserID: user.ID}
+	err = initializers.DB.Create(&tag).Error
+	// A concurrent request may have taken the name since the check above
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Tag already exists",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create tag",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.TagResponse{
+			ID:        tag.ID,
+			Name:      tag.Name,
+			CreatedAt: tag.CreatedAt,
+		},
+	})
+}
+
+func UpdateTag(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	tag, found := findUserTag(c, user)
+	if !found {
+		return
+	}
+
+	var req dtos.TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	taken, err := tagNameTaken(user.ID, name, tag.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check tag name",
+		})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Tag already exists",
+		})
+		return
+	}
+
+	err = initializers.DB.Model(&tag).Update("name", name).Error
+	// A concurrent request may have taken the name since the check above
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Tag already exists",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update tag",
+		})
+		return
+	}
+	tag.Name = name
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.TagResponse{
+			ID:        tag.ID,
+			Name:      tag.Name,
+			CreatedAt: tag.CreatedAt,
+		},
+	})
+}
+
+func DeleteTag(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	tag, found := findUserTag(c, user)
+	if !found {
+		return
+	}
+
+	// Deleting the tag removes it from its links through the join table's cascade
+	if err := initializers.DB.Delete(&tag).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete tag",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Message: "Tag deleted successfully",
+	})
+}
+
+func GetTagStats(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	tag, found := findUserTag(c, user)
+	if !found {
+		return
+	}
+
+	// Aggregate the counters of every live link carrying the tag
+	var totals struct {
+		Links       int
+		Clicks      int
+		LastClicked *time.Time
+	}
+	err := initializers.DB.Table("link_tags").
+		Select("COUNT(links.id) AS links, COALESCE(SUM(links.clicks), 0) AS clicks, MAX(links.last_clicked_at) AS last_clicked").
+		Joins("JOIN links ON links.id = link_tags.link_id AND links.deleted_at IS NULL").
+		Where("link_tags.tag_id = ?", tag.ID).
+		Scan(&totals).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch tag stats",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.TagStatsResponse{
+			TagID:       tag.ID,
+			Name:        tag.Name,
+			Links:       totals.Links,
+			Clicks:      totals.Clicks,
+			LastClicked: totals.LastClicked,
+		},
+	})
+}
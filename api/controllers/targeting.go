@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/geo"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/useragent"
+	"github.com/gin-gonic/gin"
+)
+
+// visitor is what targeting rules match against
+type visitor struct {
+	useragent.Agent
+	Language string
+	Country  string
+	// ID is the same for every request from the same IP and user agent
+	ID string
+}
+
+// newVisitor describes the client of the current request
+func newVisitor(c *gin.Context) visitor {
+	return visitor{
+		Agent:    initializers.UserAgentParser.Parse(c.Request.UserAgent()),
+		Language: preferredLanguage(c.GetHeader("Accept-Language")),
+		Country:  geo.CountryOf(c.ClientIP()),
+		ID:       visitorID(c.ClientIP(), c.Request.UserAgent()),
+	}
+}
+
+// visitorID keys a visitor's IP and user agent with the server secret, so visitors
+// can be counted without keeping their IP
+func visitorID(ip, userAgent string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("SECRET_KEY")))
+	mac.Write([]byte(ip + "|" + userAgent))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// unknownAgentName returns a description of the first OS, device or browser in
+// rule that the User-Agent parser never reports, since the rule couldn't match it
+func unknownAgentName(rule dtos.TargetingRuleRequest) string {
+	for _, condition := range []struct {
+		kind   string
+		label  string
+		values []string
+	}{
+		{useragent.KindOS, "OS", rule.OS},
+		{useragent.KindDevice, "device", rule.Devices},
+		{useragent.KindBrowser, "browser", rule.Browsers},
+	} {
+		names := initializers.UserAgentParser.Names(condition.kind)
+		for _, value := range splitList(joinList(condition.values)) {
+			if !slices.Contains(names, value) {
+				return "unknown " + condition.label + " \"" + value + "\""
+			}
+		}
+	}
+	return ""
+}
+
+// preferredLanguage returns the first language of an Accept-Language header,
+// lowercased, e.g. "pt-br" for "pt-BR,pt;q=0.9,en;q=0.8"
+func preferredLanguage(header string) string {
+	first, _, _ := strings.Cut(header, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "*" {
+		return ""
+	}
+	return tag
+}
+
+// joinList and splitList convert rule conditions to and from their stored form
+func joinList(values []string) string {
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			normalized = append(normalized, value)
+		}
+	}
+	return strings.Join(normalized, ",")
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
+
+// listMatches reports whether value satisfies a stored condition; empty conditions match anything
+func listMatches(list, value string) bool {
+	if list == "" {
+		return true
+	}
+	for _, item := range splitList(list) {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// languageMatches is like listMatches, except that a rule language without a
+// region ("pt") also matches the regional variants ("pt-br")
+func languageMatches(list, language string) bool {
+	if list == "" {
+		return true
+	}
+	primary, _, _ := strings.Cut(language, "-")
+	for _, item := range splitList(list) {
+		if item == language || item == primary {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleMatches(rule models.TargetingRule, v visitor) bool {
+	return listMatches(rule.OS, v.OS) &&
+		listMatches(rule.Devices, v.Device) &&
+		listMatches(rule.Browsers, v.Browser) &&
+		languageMatches(rule.Languages, v.Language) &&
+		listMatches(rule.Countries, v.Country)
+}
+
+// matchTargetingRule returns the first of the (ordered) rules matching v, if any
+func matchTargetingRule(rules []models.TargetingRule, v visitor) *models.TargetingRule {
+	for i := range rules {
+		if ruleMatches(rules[i], v) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func toTargetingRuleResponse(rule models.TargetingRule) dtos.TargetingRuleResponse {
+	return dtos.TargetingRuleResponse{
+		ID:             rule.ID,
+		Position:       rule.Position,
+		Name:           rule.Name,
+		OS:             splitList(rule.OS),
+		Devices:        splitList(rule.Devices),
+		Browsers:       splitList(rule.Browsers),
+		Languages:      splitList(rule.Languages),
+		Countries:      splitList(rule.Countries),
+		DestinationURL: rule.DestinationURL,
+	}
+}
+
+func GetTargetingRules(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Get link ID from URL param
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return
+	}
+
+	// Find the link with its rules in evaluation order
+	var link models.Link
+	if err := initializers.DB.Preload("TargetingRules", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return
+	}
+
+	// Check ownership
+	if link.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You don't have permission to view this link",
+		})
+		return
+	}
+
+	ruleResponses := make([]dtos.TargetingRuleResponse, 0, len(link.TargetingRules))
+	for _, rule := range link.TargetingRules {
+		ruleResponses = append(ruleResponses, toTargetingRuleResponse(rule))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    ruleResponses,
+	})
+}
+
+func SetTargetingRules(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Get link ID from URL param
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return
+	}
+
+	// Find the link
+	var link models.Link
+	if err := initializers.DB.First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return
+	}
+
+	// Check ownership
+	if link.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You don't have permission to update this link",
+		})
+		return
+	}
+
+	if rejectFlaggedUser(c, user) {
+		return
+	}
+
+	var req dtos.SetTargetingRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// Rule destinations go through the same screening as the link's own
+	rules := make([]models.TargetingRule, 0, len(req.Rules))
+	for i, ruleReq := range req.Rules {
+		if err := screenURL(ruleReq.DestinationURL); err != nil {
+			c.JSON(err.Status, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Rule " + strconv.Itoa(i+1) + ": " + err.Message,
+			})
+			return
+		}
+		if unknown := unknownAgentName(ruleReq); unknown != "" {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Rule " + strconv.Itoa(i+1) + ": " + unknown,
+			})
+			return
+		}
+		rules = append(rules, models.TargetingRule{
+			LinkID:         link.ID,
+			Position:       i,
+			Name:           ruleReq.Name,
+			OS:             joinList(ruleReq.OS),
+			Devices:        joinList(ruleReq.Devices),
+			Browsers:       joinList(ruleReq.Browsers),
+			Languages:      joinList(ruleReq.Languages),
+			Countries:      joinList(ruleReq.Countries),
+			DestinationURL: ruleReq.DestinationURL,
+		})
+	}
+
+	// Replace the whole list
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("link_id = ?", link.ID).Delete(&models.TargetingRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) > 0 {
+			if err := tx.Create(&rules).Error; err != nil {
+				return err
+			}
+		}
+		return recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkUpdate,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			Metadata:   map[string]interface{}{"targetingRules": len(rules)},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to save targeting rules",
+		})
+		return
+	}
+
+	ruleResponses := make([]dtos.TargetingRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		ruleResponses = append(ruleResponses, toTargetingRuleResponse(rule))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    ruleResponses,
+		Message: "Targeting rules saved",
+	})
+}
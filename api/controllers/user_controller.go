@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,12 +14,15 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
-// generateJWTToken creates a JWT token for the user
-func generateJWTToken(userID uint) (string, error) {
+// generateJWTToken creates a JWT token for the user; it stops working once the
+// user's token version is bumped
+func generateJWTToken(user models.User) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": userID,
+		"sub": user.ID,
+		"ver": user.TokenVersion,
 		"exp": time.Now().Add(time.Hour * 24 * 30).Unix(),
 	})
 
@@ -52,7 +56,23 @@ func SignUpWithToken(c *gin.Context) {
 		Name:     req.Name,
 	}
 
-	if err := initializers.DB.Create(&user).Error; err != nil {
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionSignup,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetUser,
+			TargetID:   uintPtr(user.ID),
+			After: map[string]interface{}{
+				"email": user.Email,
+				"name":  user.Name,
+				"role":  user.Role,
+			},
+		})
+	})
+	if err != nil {
 		if strings.Contains(err.Error(), "duplicate") ||
 			strings.Contains(err.Error(), "unique") {
 			c.JSON(http.StatusConflict, dtos.ErrorResponse{
@@ -70,7 +90,7 @@ func SignUpWithToken(c *gin.Context) {
 	}
 
 	// Generate JWT token directly
-	tokenString, err := generateJWTToken(user.ID)
+	tokenString, err := generateJWTToken(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
 			Success: false,
@@ -110,6 +130,10 @@ func LoginWithToken(c *gin.Context) {
 
 	if result.Error != nil {
 		fmt.Printf("Login failed: User not found for email %s\n", req.Email)
+		recordAuditEvent(initializers.DB, c, auditEntry{
+			Action:   models.AuditActionLoginFailure,
+			Metadata: map[string]interface{}{"email": strings.ToLower(req.Email), "reason": "unknown_email"},
+		})
 		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Invalid email or password",
@@ -121,6 +145,12 @@ func LoginWithToken(c *gin.Context) {
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
 		fmt.Printf("Login failed: Password mismatch for user %s\n", req.Email)
+		recordAuditEvent(initializers.DB, c, auditEntry{
+			Action:     models.AuditActionLoginFailure,
+			TargetType: models.AuditTargetUser,
+			TargetID:   uintPtr(user.ID),
+			Metadata:   map[string]interface{}{"email": user.Email, "reason": "password_mismatch"},
+		})
 		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
 			Success: false,
 			Error:   "Invalid email or password",
@@ -128,8 +158,15 @@ func LoginWithToken(c *gin.Context) {
 		return
 	}
 
+	recordAuditEvent(initializers.DB, c, auditEntry{
+		Action:     models.AuditActionLoginSuccess,
+		ActorID:    uintPtr(user.ID),
+		TargetType: models.AuditTargetUser,
+		TargetID:   uintPtr(user.ID),
+	})
+
 	// Generate JWT token
-	tokenString, err := generateJWTToken(user.ID)
+	tokenString, err := generateJWTToken(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
 			Success: false,
@@ -178,7 +215,178 @@ func GetCurrentUser(c *gin.Context) {
 			Name:      user.Name,
 			Email:     user.Email,
 			Role:      user.Role,
+			FlaggedAt: user.FlaggedAt,
 			CreatedAt: user.CreatedAt,
 		},
 	})
 }
+
+// RevokeTokens invalidates every token issued to the current user and returns a
+// fresh one for the caller
+func RevokeTokens(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).
+			Update("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Select("token_version").First(&user, user.ID).Error; err != nil {
+			return err
+		}
+		return recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionTokenRevoke,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetUser,
+			TargetID:   uintPtr(user.ID),
+			Metadata:   map[string]interface{}{"tokenVersion": user.TokenVersion},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to revoke tokens",
+		})
+		return
+	}
+
+	tokenString, err := generateJWTToken(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.LoginResponse{
+			ID:    user.ID,
+			Name:  user.Name,
+			Email: user.Email,
+			Token: tokenString,
+			Role:  user.Role,
+		},
+		Message: "All other tokens have been revoked",
+	})
+}
+
+// UpdateUserRole lets an admin promote or demote another user
+func UpdateUserRole(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	admin, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+		return
+	}
+
+	var req dtos.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// An admin demoting themselves could leave no one able to undo it
+	if uint(userID) == admin.ID {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You can't change your own role",
+		})
+		return
+	}
+
+	var target models.User
+	if err := initializers.DB.First(&target, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return
+	}
+
+	if target.Role == req.Role {
+		c.JSON(http.StatusConflict, dtos.ErrorResponse{
+			Success: false,
+			Error:   "User already has this role",
+		})
+		return
+	}
+
+	previousRole := target.Role
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&target).Update("role", req.Role).Error; err != nil {
+			return err
+		}
+		return recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionRoleChange,
+			ActorID:    uintPtr(admin.ID),
+			TargetType: models.AuditTargetUser,
+			TargetID:   uintPtr(target.ID),
+			Before:     map[string]interface{}{"role": previousRole},
+			After:      map[string]interface{}{"role": req.Role},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update role",
+		})
+		return
+	}
+	target.Role = req.Role
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data: dtos.UserResponse{
+			ID:        target.ID,
+			Name:      target.Name,
+			Email:     target.Email,
+			Role:      target.Role,
+			FlaggedAt: target.FlaggedAt,
+			CreatedAt: target.CreatedAt,
+		},
+		Message: "Role updated",
+	})
+}
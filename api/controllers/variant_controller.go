@@ -0,0 +1,396 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// toVariantResponses converts a link's variants, working out each one's share of the traffic
+func toVariantResponses(variants []models.LinkVariant) []dtos.VariantResponse {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+
+	responses := make([]dtos.VariantResponse, 0, len(variants))
+	for _, variant := range variants {
+		responses = append(responses, dtos.VariantResponse{
+			ID:             variant.ID,
+			Name:           variant.Name,
+			DestinationURL: variant.DestinationURL,
+			Weight:         variant.Weight,
+			Share:          float64(variant.Weight) / float64(total),
+		})
+	}
+	return responses
+}
+
+// linkVariantStats counts the clicks each variant of a link received, including
+// variants that have since been deleted
+func linkVariantStats(linkID uint, includeBots bool) ([]dtos.VariantStatsResponse, error) {
+	var variants []models.LinkVariant
+	if err := initializers.DB.Where("link_id = ?", linkID).Order("id").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		VariantID uint
+		Clicks    int64
+	}
+	if err := linkClicks(linkID, includeBots).
+		Select("variant_id, COUNT(*) AS clicks").
+		Where("variant_id IS NOT NULL").
+		Group("variant_id").
+		Order("variant_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	clicksByVariant := map[uint]int64{}
+	for _, count := range counts {
+		clicksByVariant[count.VariantID] = count.Clicks
+	}
+
+	stats := make([]dtos.VariantStatsResponse, 0, len(variants)+len(counts))
+	for _, variant := range variants {
+		stats = append(stats, dtos.VariantStatsResponse{
+			ID:             variant.ID,
+			Name:           variant.Name,
+			DestinationURL: variant.DestinationURL,
+			Clicks:         clicksByVariant[variant.ID],
+		})
+		delete(clicksByVariant, variant.ID)
+	}
+	for _, count := range counts {
+		if clicks, deleted := clicksByVariant[count.VariantID]; deleted {
+			stats = append(stats, dtos.VariantStatsResponse{ID: count.VariantID, Deleted: true, Clicks: clicks})
+		}
+	}
+	return stats, nil
+}
+
+// findUserLinkVariants loads the link in the :id URL param with its variants and checks
+// that it belongs to user, writing the error response if it doesn't
+func findUserLinkVariants(c *gin.Context, user models.User) (models.Link, bool) {
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid link ID",
+		})
+		return models.Link{}, false
+	}
+
+	var link models.Link
+	if err := initializers.DB.Preload("Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&link, linkID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Link not found",
+		})
+		return models.Link{}, false
+	}
+
+	if link.UserID != user.ID {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "You don't have permission to access this link",
+		})
+		return models.Link{}, false
+	}
+
+	return link, true
+}
+
+// findVariant returns the variant of link in the :variantId URL param,
+// writing the error response if there is none
+func findVariant(c *gin.Context, link models.Link) (*models.LinkVariant, bool) {
+	variantID, err := strconv.ParseUint(c.Param("variantId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid variant ID",
+		})
+		return nil, false
+	}
+
+	for i := range link.Variants {
+		if uint64(link.Variants[i].ID) == variantID {
+			return &link.Variants[i], true
+		}
+	}
+
+	c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+		Success: false,
+		Error:   "Variant not found",
+	})
+	return nil, false
+}
+
+func GetVariants(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	link, found := findUserLinkVariants(c, user)
+	if !found {
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toVariantResponses(link.Variants),
+	})
+}
+
+func CreateVariant(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	link, found := findUserLinkVariants(c, user)
+	if !found {
+		return
+	}
+
+	if rejectFlaggedUser(c, user) {
+		return
+	}
+
+	var req dtos.CreateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if err := screenURL(req.DestinationURL); err != nil {
+		c.JSON(err.Status, dtos.ErrorResponse{
+			Success: false,
+			Error:   err.Message,
+		})
+		return
+	}
+
+	variant := models.LinkVariant{
+		LinkID:         link.ID,
+		Name:           req.Name,
+		DestinationURL: req.DestinationURL,
+		Weight:         req.Weight,
+	}
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&variant).Error; err != nil {
+			return err
+		}
+		return recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkUpdate,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			Metadata:   map[string]interface{}{"variantCreated": variant.ID},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create variant",
+		})
+		return
+	}
+
+	link.Variants = append(link.Variants, variant)
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    toVariantResponses(link.Variants),
+		Message: "Variant created successfully",
+	})
+}
+
+func UpdateVariant(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	link, found := findUserLinkVariants(c, user)
+	if !found {
+		return
+	}
+
+	variant, found := findVariant(c, link)
+	if !found {
+		return
+	}
+
+	var req dtos.UpdateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	// Apply the requested changes
+	if req.Name != nil {
+		variant.Name = *req.Name
+	}
+	if req.DestinationURL != nil {
+		if rejectFlaggedUser(c, user) {
+			return
+		}
+		if err := screenURL(*req.DestinationURL); err != nil {
+			c.JSON(err.Status, dtos.ErrorResponse{
+				Success: false,
+				Error:   err.Message,
+			})
+			return
+		}
+		variant.DestinationURL = *req.DestinationURL
+	}
+	if req.Weight != nil {
+		variant.Weight = *req.Weight
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(variant).Error; err != nil {
+			return err
+		}
+		return recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkUpdate,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			Metadata:   map[string]interface{}{"variantUpdated": variant.ID},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update variant",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toVariantResponses(link.Variants),
+	})
+}
+
+func DeleteVariant(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	link, found := findUserLinkVariants(c, user)
+	if !found {
+		return
+	}
+
+	variant, found := findVariant(c, link)
+	if !found {
+		return
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(variant).Error; err != nil {
+			return err
+		}
+		return recordAuditEvent(tx, c, auditEntry{
+			Action:     models.AuditActionLinkUpdate,
+			ActorID:    uintPtr(user.ID),
+			TargetType: models.AuditTargetLink,
+			TargetID:   uintPtr(link.ID),
+			Metadata:   map[string]interface{}{"variantDeleted": variant.ID},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete variant",
+		})
+		return
+	}
+
+	remaining := make([]models.LinkVariant, 0, len(link.Variants))
+	for _, other := range link.Variants {
+		if other.ID != variant.ID {
+			remaining = append(remaining, other)
+		}
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toVariantResponses(remaining),
+		Message: "Variant deleted successfully",
+	})
+}
@@ -0,0 +1,498 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+// deliveryCursor is the position encoded in webhook delivery cursors
+type deliveryCursor struct {
+	ID uint `json:"id"`
+}
+
+func toWebhookResponse(webhook models.Webhook) dtos.WebhookResponse {
+	return dtos.WebhookResponse{
+		ID:          webhook.ID,
+		URL:         webhook.URL,
+		Events:      splitList(webhook.Events),
+		Description: webhook.Description,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(delivery models.WebhookDelivery) dtos.WebhookDeliveryResponse {
+	return dtos.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		Error:          delivery.Error,
+		DurationMs:     delivery.DurationMs,
+		DeliveredAt:    delivery.DeliveredAt,
+		RedeliveryOf:   delivery.RedeliveryOf,
+		Payload:        json.RawMessage(delivery.Payload),
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+// findUserWebhook loads a webhook by the :id URL param and checks that it belongs to user,
+// writing the error response if it doesn't
+func findUserWebhook(c *gin.Context, user models.User) (models.Webhook, bool) {
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid webhook ID",
+		})
+		return models.Webhook{}, false
+	}
+
+	var webhook models.Webhook
+	if err := initializers.DB.Where("user_id = ?", user.ID).First(&webhook, webhookID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Webhook not found",
+		})
+		return models.Webhook{}, false
+	}
+
+	return webhook, true
+}
+
+// sendNow attempts a new delivery right away so the caller sees the outcome. If
+// the attempt fails the delivery stays queued for retries like any other.
+func sendNow(c *gin.Context, webhook models.Webhook, delivery models.WebhookDelivery) {
+	if err := initializers.DB.Omit("Webhook").Create(&delivery).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to queue delivery",
+		})
+		return
+	}
+
+	if err := initializers.WebhookDispatcher.Send(c.Request.Context(), initializers.DB, webhook, &delivery); err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to record delivery",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toWebhookDeliveryResponse(delivery),
+	})
+}
+
+func GetWebhooks(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	var hooks []models.Webhook
+	if err := initializers.DB.Where("user_id = ?", user.ID).Order("id").Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch webhooks",
+		})
+		return
+	}
+
+	webhookResponses := make([]dtos.WebhookResponse, 0, len(hooks))
+	for _, hook := range hooks {
+		webhookResponses = append(webhookResponses, toWebhookResponse(hook))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    webhookResponses,
+	})
+}
+
+func CreateWebhook(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	// Parse request body
+	var req dtos.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if err := screenURL(req.URL); err != nil {
+		c.JSON(err.Status, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Webhook " + err.Message,
+		})
+		return
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate webhook secret",
+		})
+		return
+	}
+
+	webhook := models.Webhook{
+		UserID:      user.ID,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      joinList(req.Events),
+		Description: req.Description,
+		Active:      true,
+	}
+	if err := initializers.DB.Create(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create webhook",
+		})
+		return
+	}
+
+	// The secret is shown once, so the receiver can verify signatures
+	response := toWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+
+	c.JSON(http.StatusCreated, dtos.SuccessResponse{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func UpdateWebhook(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	webhook, found := findUserWebhook(c, user)
+	if !found {
+		return
+	}
+
+	// Parse request body
+	var req dtos.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	if req.URL != nil {
+		if err := screenURL(*req.URL); err != nil {
+			c.JSON(err.Status, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Webhook " + err.Message,
+			})
+			return
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		webhook.Events = joinList(req.Events)
+	}
+	if req.Description != nil {
+		webhook.Description = *req.Description
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := initializers.DB.Save(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update webhook",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    toWebhookResponse(webhook),
+	})
+}
+
+func DeleteWebhook(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	webhook, found := findUserWebhook(c, user)
+	if !found {
+		return
+	}
+
+	// Deliveries are deleted with the webhook
+	if err := initializers.DB.Delete(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete webhook",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Message: "Webhook deleted successfully",
+	})
+}
+
+func GetWebhookDeliveries(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	webhook, found := findUserWebhook(c, user)
+	if !found {
+		return
+	}
+
+	var query dtos.WebhookDeliveryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid input: " + err.Error(),
+		})
+		return
+	}
+
+	db := initializers.DB.Where("webhook_id = ?", webhook.ID)
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Event != "" {
+		db = db.Where("event = ?", query.Event)
+	}
+
+	// Resume after the cursor position (newest first)
+	if query.Cursor != "" {
+		var cursor deliveryCursor
+		if err := decodeCursor(query.Cursor, &cursor); err != nil {
+			c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Invalid cursor",
+			})
+			return
+		}
+		db = db.Where("id < ?", cursor.ID)
+	}
+
+	// Fetch one extra row to know whether there is another page
+	limit := pageLimit(query.Limit)
+	var deliveries []models.WebhookDelivery
+	if err := db.Order("id DESC").Limit(limit + 1).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch deliveries",
+		})
+		return
+	}
+
+	paging := dtos.PageInfo{Limit: limit}
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+		paging.HasMore = true
+		paging.NextCursor = encodeCursor(deliveryCursor{ID: deliveries[len(deliveries)-1].ID})
+	}
+
+	deliveryResponses := make([]dtos.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveryResponses = append(deliveryResponses, toWebhookDeliveryResponse(delivery))
+	}
+
+	c.JSON(http.StatusOK, dtos.SuccessResponse{
+		Success: true,
+		Data:    deliveryResponses,
+		Paging:  &paging,
+	})
+}
+
+// RedeliverWebhookDelivery sends an earlier delivery's event again as a new
+// delivery with the same event ID
+func RedeliverWebhookDelivery(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	webhook, found := findUserWebhook(c, user)
+	if !found {
+		return
+	}
+
+	deliveryID, err := strconv.ParseUint(c.Param("deliveryId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Invalid delivery ID",
+		})
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := initializers.DB.Where("webhook_id = ?", webhook.ID).First(&original, deliveryID).Error; err != nil {
+		c.JSON(http.StatusNotFound, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Delivery not found",
+		})
+		return
+	}
+
+	sendNow(c, webhook, models.WebhookDelivery{
+		WebhookID:    webhook.ID,
+		EventID:      original.EventID,
+		Event:        original.Event,
+		Payload:      original.Payload,
+		RedeliveryOf: &original.ID,
+		Status:       models.WebhookDeliveryPending,
+	})
+}
+
+// PingWebhook sends a test event to a webhook, whatever events it subscribes to
+func PingWebhook(c *gin.Context) {
+	// Get user from context
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	user, ok := userInterface.(models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get user data",
+		})
+		return
+	}
+
+	webhook, found := findUserWebhook(c, user)
+	if !found {
+		return
+	}
+
+	delivery, err := webhooks.NewDelivery(webhook, models.WebhookEventPing, gin.H{"webhookId": webhook.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Failed to build ping",
+		})
+		return
+	}
+
+	sendNow(c, webhook, delivery)
+}
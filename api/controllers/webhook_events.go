@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"log"
+
+	"github.com/caiohportella/blinky/webhooks"
+	"gorm.io/gorm"
+)
+
+// enqueueWebhookEvent queues event for the webhooks of userID through tx. Pass
+// the transaction making the change so the event is queued only if the change is
+// kept; failures are logged and returned so that transaction can roll back.
+func enqueueWebhookEvent(tx *gorm.DB, userID uint, event string, data interface{}) error {
+	if err := webhooks.Enqueue(tx, userID, event, data); err != nil {
+		log.Printf("Failed to queue webhook event %s for user %d: %v", event, userID, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package domains
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+)
+
+const (
+	recordNamePrefix  = "_blinky-verification."
+	recordValuePrefix = "blinky-verification="
+)
+
+// TXTResolver looks up DNS TXT records. *net.Resolver satisfies it.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Resolver is used to verify domain ownership. Swap it for a StaticResolver
+// in tests or local setups without real DNS.
+var Resolver TXTResolver = net.DefaultResolver
+
+// StaticResolver is an in-memory TXTResolver keyed by record name
+type StaticResolver map[string][]string
+
+func (r StaticResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, found := r[strings.TrimSuffix(name, ".")]
+	if !found {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// NormalizeHost lowercases a host and strips its port and trailing dot
+func NormalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// GenerateToken returns a random verification token
+func GenerateToken() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// RecordName is the TXT record name that must hold the verification value for hostname
+func RecordName(hostname string) string {
+	return recordNamePrefix + hostname
+}
+
+// RecordValue is the TXT record value proving ownership with token
+func RecordValue(token string) string {
+	return recordValuePrefix + token
+}
+
+// Verify reports whether hostname publishes the TXT record for token.
+// A missing record is not an error; it just isn't verified.
+func Verify(ctx context.Context, resolver TXTResolver, hostname, token string) (bool, error) {
+	records, err := resolver.LookupTXT(ctx, RecordName(hostname))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	expected := RecordValue(token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,62 @@
+package domains
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type failingResolver struct{}
+
+func (failingResolver) LookupTXT(context.Context, string) ([]string, error) {
+	return nil, errors.New("server misbehaving")
+}
+
+func TestVerify(t *testing.T) {
+	resolver := StaticResolver{
+		RecordName("go.example.com"):    {"v=spf1 -all", " " + RecordValue("right-token") + " "},
+		RecordName("links.example.org"): {RecordValue("someone-elses-token")},
+	}
+
+	tests := []struct {
+		name     string
+		hostname string
+		token    string
+		want     bool
+	}{
+		{"matching record", "go.example.com", "right-token", true},
+		{"mismatching token", "go.example.com", "wrong-token", false},
+		{"record of another claim", "links.example.org", "right-token", false},
+		{"no record", "missing.example.net", "right-token", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(context.Background(), resolver, tt.hostname, tt.token)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyLookupFailure(t *testing.T) {
+	if _, err := Verify(context.Background(), failingResolver{}, "go.example.com", "token"); err == nil {
+		t.Error("Verify() hid a failed DNS lookup")
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	for input, want := range map[string]string{
+		"Go.Example.COM":      "go.example.com",
+		"go.example.com.":     "go.example.com",
+		"go.example.com:8443": "go.example.com",
+		" go.example.com ":    "go.example.com",
+	} {
+		if got := NormalizeHost(input); got != want {
+			t.Errorf("NormalizeHost(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package dtos
+
+import "time"
+
+type ReportLinkRequest struct {
+	Reason  string `json:"reason" binding:"required,oneof=phishing malware spam illegal other"`
+	Details string `json:"details,omitempty" binding:"max=2000"`
+	Email   string `json:"email,omitempty" binding:"omitempty,email"`
+}
+
+type AbuseReportQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending actioned dismissed"`
+	LinkID *uint  `form:"linkId"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// ResolveAbuseReportRequest either disables the reported link or dismisses the report.
+// Resolving a report resolves every other pending report of the same link too.
+type ResolveAbuseReportRequest struct {
+	Action string `json:"action" binding:"required,oneof=disable dismiss"`
+	Note   string `json:"note,omitempty" binding:"max=500"`
+}
+
+type DisableLinkRequest struct {
+	Reason string `json:"reason" binding:"required,min=1,max=500"`
+}
+
+type AbuseReportResponse struct {
+	ID             uint         `json:"id"`
+	Reason         string       `json:"reason"`
+	Details        string       `json:"details,omitempty"`
+	ReporterEmail  string       `json:"reporterEmail,omitempty"`
+	Status         string       `json:"status"`
+	Link           LinkResponse `json:"link"`
+	ReviewedByID   *uint        `json:"reviewedById,omitempty"`
+	ReviewedAt     *time.Time   `json:"reviewedAt,omitempty"`
+	ResolutionNote string       `json:"resolutionNote,omitempty"`
+	CreatedAt      time.Time    `json:"createdAt"`
+}
+
+// InterstitialPayload is what visitors of a disabled link see instead of the destination
+type InterstitialPayload struct {
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+	Reason    string `json:"reason,omitempty"`
+	ShortCode string `json:"shortCode"`
+}
+
+type DisabledLinkResponse struct {
+	Success      bool                `json:"success"`
+	Error        string              `json:"error"`
+	Interstitial InterstitialPayload `json:"interstitial"`
+}
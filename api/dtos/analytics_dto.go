@@ -0,0 +1,63 @@
+package dtos
+
+import "time"
+
+// AnalyticsQuery selects the days covered by an overview, both ends included
+type AnalyticsQuery struct {
+	From  time.Time `form:"from" time_format:"2006-01-02"`
+	To    time.Time `form:"to" time_format:"2006-01-02"`
+	Limit int       `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// AnalyticsTotals counts clicks, visits and visitors. A visit is a visitor's first
+// click on a link on a given UTC day, so a visitor who comes back on another day,
+// or opens several links, makes several visits but counts as one visitor.
+type AnalyticsTotals struct {
+	Clicks   int64 `json:"clicks"`
+	Visits   int64 `json:"visits"`
+	Visitors int64 `json:"visitors"`
+}
+
+// AnalyticsChange is the relative change from the previous period in percent,
+// nil when the previous period had no clicks
+type AnalyticsChange struct {
+	Clicks   *float64 `json:"clicks"`
+	Visits   *float64 `json:"visits"`
+	Visitors *float64 `json:"visitors"`
+}
+
+type AnalyticsLinkCount struct {
+	LinkID      uint   `json:"linkId"`
+	ShortCode   string `json:"shortCode"`
+	OriginalURL string `json:"originalUrl"`
+	Clicks      int64  `json:"clicks"`
+	Visits      int64  `json:"visits"`
+}
+
+type AnalyticsValueCount struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+type AnalyticsDayCount struct {
+	Date     string `json:"date"`
+	Clicks   int64  `json:"clicks"`
+	Visits   int64  `json:"visits"`
+	Visitors int64  `json:"visitors"`
+}
+
+// AnalyticsOverviewResponse sums up the clicks on all of a user's links over a period
+type AnalyticsOverviewResponse struct {
+	From         string                `json:"from"`
+	To           string                `json:"to"`
+	Totals       AnalyticsTotals       `json:"totals"`
+	Previous     AnalyticsTotals       `json:"previous"`
+	Change       AnalyticsChange       `json:"change"`
+	TopLinks     []AnalyticsLinkCount  `json:"topLinks"`
+	TopReferrers []AnalyticsValueCount `json:"topReferrers"`
+	TopCountries []AnalyticsValueCount `json:"topCountries"`
+	TopDevices   []AnalyticsValueCount `json:"topDevices"`
+	TopBrowsers  []AnalyticsValueCount `json:"topBrowsers"`
+	TopOS        []AnalyticsValueCount `json:"topOs"`
+	Daily        []AnalyticsDayCount   `json:"daily"`
+}
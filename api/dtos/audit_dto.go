@@ -0,0 +1,30 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditQuery struct {
+	Action     string    `form:"action"`
+	ActorID    *uint     `form:"actorId"`
+	TargetType string    `form:"targetType"`
+	TargetID   *uint     `form:"targetId"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor     string    `form:"cursor"`
+	Limit      int       `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type AuditEventResponse struct {
+	ID         uint            `json:"id"`
+	Action     string          `json:"action"`
+	ActorID    *uint           `json:"actorId"`
+	TargetType string          `json:"targetType,omitempty"`
+	TargetID   *uint           `json:"targetId,omitempty"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"userAgent"`
+	Changes    json.RawMessage `json:"changes,omitempty"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,22 @@
+package dtos
+
+import "time"
+
+type CreateDomainRequest struct {
+	Hostname string `json:"hostname" binding:"required,fqdn,max=253"`
+}
+
+type DomainVerificationRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type DomainResponse struct {
+	ID           uint                     `json:"id"`
+	Hostname     string                   `json:"hostname"`
+	Verified     bool                     `json:"verified"`
+	VerifiedAt   *time.Time               `json:"verifiedAt,omitempty"`
+	Verification DomainVerificationRecord `json:"verification"`
+	CreatedAt    time.Time                `json:"createdAt"`
+}
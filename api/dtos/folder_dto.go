@@ -0,0 +1,23 @@
+package dtos
+
+import "time"
+
+type CreateFolderRequest struct {
+	Name     string `json:"name" binding:"required,min=1,max=100"`
+	ParentID *uint  `json:"parentId,omitempty"`
+}
+
+// UpdateFolderRequest renames and/or moves a folder. A parentId of 0 moves
+// the folder to the top level.
+type UpdateFolderRequest struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	ParentID *uint   `json:"parentId,omitempty"`
+}
+
+type FolderResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  *uint     `json:"parentId"`
+	LinkCount int       `json:"linkCount"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -3,6 +3,7 @@ package dtos
 type SuccessResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
+	Paging  *PageInfo   `json:"paging,omitempty"`
 	Message string      `json:"message,omitempty"`
 }
 
@@ -14,3 +15,11 @@ type ErrorResponse struct {
 type IDResponse struct {
 	ID uint `json:"id"`
 }
+
+// PageInfo describes a page of a cursor-paginated list. Pass NextCursor back
+// as the cursor query param to fetch the following page.
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	HasMore    bool   `json:"hasMore"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
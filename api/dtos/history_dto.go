@@ -0,0 +1,35 @@
+package dtos
+
+import "time"
+
+type LinkHistoryQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// LinkVersionResponse is one entry of a link's history: its destination and
+// settings as they were from CreatedAt until the next version
+type LinkVersionResponse struct {
+	Version          int        `json:"version"`
+	Current          bool       `json:"current"`
+	AuthorID         *uint      `json:"authorId"`
+	AuthorName       string     `json:"authorName,omitempty"`
+	RestoredFrom     *int       `json:"restoredFrom,omitempty"`
+	OriginalURL      string     `json:"originalUrl"`
+	ActiveFrom       *time.Time `json:"activeFrom,omitempty"`
+	ActiveUntil      *time.Time `json:"activeUntil,omitempty"`
+	FallbackURL      string     `json:"fallbackUrl,omitempty"`
+	StickyRotation   string     `json:"stickyRotation,omitempty"`
+	QueryPassthrough bool       `json:"queryPassthrough"`
+	QueryPrecedence  string     `json:"queryPrecedence,omitempty"`
+	UTMParams
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// VersionStatsResponse counts the clicks served while a version was current.
+// Clicks recorded before the link had a history are reported under version 0.
+type VersionStatsResponse struct {
+	Version     int    `json:"version"`
+	OriginalURL string `json:"originalUrl,omitempty"`
+	Clicks      int64  `json:"clicks"`
+}
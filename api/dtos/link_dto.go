@@ -1,23 +1,230 @@
 package dtos
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type CreateLinkRequest struct {
-	OriginalURL string `json:"originalUrl" binding:"required,url"`
-	CustomCode  string `json:"customCode,omitempty"`
+	OriginalURL      string     `json:"originalUrl" binding:"required,url"`
+	CustomCode       string     `json:"customCode,omitempty"`
+	DomainID         *uint      `json:"domainId,omitempty"`
+	Tags             []string   `json:"tags,omitempty" binding:"omitempty,max=20,dive,min=1,max=50"`
+	FolderID         *uint      `json:"folderId,omitempty"`
+	ActiveFrom       *time.Time `json:"activeFrom,omitempty"`
+	ActiveUntil      *time.Time `json:"activeUntil,omitempty"`
+	FallbackURL      string     `json:"fallbackUrl,omitempty" binding:"omitempty,url"`
+	QueryPassthrough bool       `json:"queryPassthrough,omitempty"`
+	QueryPrecedence  string     `json:"queryPrecedence,omitempty" binding:"omitempty,oneof=destination incoming"`
+	UTMParams
+}
+
+// UTMParams are added to the destination on redirect. Empty fields are left out.
+type UTMParams struct {
+	UTMSource   string `json:"utmSource,omitempty" binding:"max=200"`
+	UTMMedium   string `json:"utmMedium,omitempty" binding:"max=200"`
+	UTMCampaign string `json:"utmCampaign,omitempty" binding:"max=200"`
+	UTMTerm     string `json:"utmTerm,omitempty" binding:"max=200"`
+	UTMContent  string `json:"utmContent,omitempty" binding:"max=200"`
+}
+
+// UpdateLinkRequest changes the given fields of a link; omitted fields are
+// left untouched. Tags replace the current set, and a folderId of 0 removes
+// the link from its folder. An empty fallbackUrl removes it, and a null
+// activeFrom or activeUntil removes that end of the activation window.
+type UpdateLinkRequest struct {
+	OriginalURL    *string      `json:"originalUrl,omitempty" binding:"omitempty,url"`
+	Tags           []string     `json:"tags,omitempty" binding:"omitempty,max=20,dive,min=1,max=50"`
+	FolderID       *uint        `json:"folderId,omitempty"`
+	ActiveFrom     NullableTime `json:"activeFrom"`
+	ActiveUntil    NullableTime `json:"activeUntil"`
+	FallbackURL    *string      `json:"fallbackUrl,omitempty" binding:"omitempty,url|len=0"`
+	StickyRotation *string      `json:"stickyRotation,omitempty" binding:"omitempty,oneof=none cookie ip"`
+	// Passthrough settings and UTM parameters; an empty UTM value removes it
+	QueryPassthrough *bool   `json:"queryPassthrough,omitempty"`
+	QueryPrecedence  *string `json:"queryPrecedence,omitempty" binding:"omitempty,oneof=destination incoming"`
+	UTMSource        *string `json:"utmSource,omitempty" binding:"omitempty,max=200"`
+	UTMMedium        *string `json:"utmMedium,omitempty" binding:"omitempty,max=200"`
+	UTMCampaign      *string `json:"utmCampaign,omitempty" binding:"omitempty,max=200"`
+	UTMTerm          *string `json:"utmTerm,omitempty" binding:"omitempty,max=200"`
+	UTMContent       *string `json:"utmContent,omitempty" binding:"omitempty,max=200"`
+}
+
+// NullableTime tells an omitted time apart from an explicit null. Set is true
+// when the field was present; Time is nil if it was null.
+type NullableTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (t *NullableTime) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	if string(data) == "null" {
+		t.Time = nil
+		return nil
+	}
+	return json.Unmarshal(data, &t.Time)
+}
+
+type LinkQuery struct {
+	Search      string    `form:"search"`
+	Domain      string    `form:"domain"`
+	DomainID    *uint     `form:"domainId"`
+	Tags        []string  `form:"tag"`
+	FolderID    *uint     `form:"folderId"`
+	Recursive   bool      `form:"recursive"`
+	CreatedFrom time.Time `form:"createdFrom" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   time.Time `form:"createdTo" time_format:"2006-01-02T15:04:05Z07:00"`
+	Status      string    `form:"status" binding:"omitempty,oneof=scheduled active ended"`
+	Broken      *bool     `form:"broken"`
+	Sort        string    `form:"sort" binding:"omitempty,oneof=created clicks lastClicked"`
+	Order       string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	Cursor      string    `form:"cursor"`
+	Limit       int       `form:"limit" binding:"omitempty,min=1,max=200"`
 }
 
 type LinkResponse struct {
-	ID          uint      `json:"id"`
-	ShortCode   string    `json:"shortCode"`
-	OriginalURL string    `json:"originalUrl"`
-	Clicks      int       `json:"clicks"`
-	Favicon     string    `json:"favicon,omitempty"`
-	UserID      uint      `json:"userId"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID               uint       `json:"id"`
+	ShortCode        string     `json:"shortCode"`
+	DomainID         *uint      `json:"domainId,omitempty"`
+	OriginalURL      string     `json:"originalUrl"`
+	Clicks           int        `json:"clicks"`
+	LastClickedAt    *time.Time `json:"lastClickedAt,omitempty"`
+	ActiveFrom       *time.Time `json:"activeFrom,omitempty"`
+	ActiveUntil      *time.Time `json:"activeUntil,omitempty"`
+	FallbackURL      string     `json:"fallbackUrl,omitempty"`
+	Status           string     `json:"status"`
+	Version          int        `json:"version"`
+	Broken           bool       `json:"broken"`
+	Tags             []string   `json:"tags"`
+	FolderID         *uint      `json:"folderId,omitempty"`
+	DisabledAt       *time.Time `json:"disabledAt,omitempty"`
+	DisabledReason   string     `json:"disabledReason,omitempty"`
+	StickyRotation   string     `json:"stickyRotation,omitempty"`
+	QueryPassthrough bool       `json:"queryPassthrough"`
+	QueryPrecedence  string     `json:"queryPrecedence,omitempty"`
+	Title            string     `json:"title,omitempty"`
+	Description      string     `json:"description,omitempty"`
+	Image            string     `json:"image,omitempty"`
+	Favicon          string     `json:"favicon,omitempty"`
+	UserID           uint       `json:"userId"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UTMParams
+
+	// Set once the destination has been health checked
+	Health *LinkHealthResponse `json:"health,omitempty"`
+}
+
+// LinkHealthResponse is the outcome of the last health check of a link's destination
+type LinkHealthResponse struct {
+	CheckedAt     time.Time       `json:"checkedAt"`
+	StatusCode    int             `json:"statusCode,omitempty"`
+	LatencyMs     int             `json:"latencyMs"`
+	RedirectChain json.RawMessage `json:"redirectChain,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	BrokenSince   *time.Time      `json:"brokenSince,omitempty"`
+}
+
+type TrashQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type TrashedLinkResponse struct {
+	LinkResponse
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
+
+// LinkStatsQuery toggles whether visits from bots and link previews are counted
+type LinkStatsQuery struct {
+	IncludeBots bool `form:"includeBots"`
 }
 
 type LinkStatsResponse struct {
-	Clicks      int        `json:"clicks"`
-	LastClicked *time.Time `json:"lastClicked,omitempty"`
+	Clicks      int                    `json:"clicks"`
+	BotClicks   int                    `json:"botClicks"`
+	LastClicked *time.Time             `json:"lastClicked,omitempty"`
+	Variants    []VariantStatsResponse `json:"variants,omitempty"`
+	UTM         *UTMStatsResponse      `json:"utm,omitempty"`
+	Versions    []VersionStatsResponse `json:"versions,omitempty"`
+}
+
+type UTMValueCount struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+// UTMStatsResponse breaks clicks down by the UTM parameters they were sent with
+type UTMStatsResponse struct {
+	Sources   []UTMValueCount `json:"sources"`
+	Mediums   []UTMValueCount `json:"mediums"`
+	Campaigns []UTMValueCount `json:"campaigns"`
+	Terms     []UTMValueCount `json:"terms"`
+	Contents  []UTMValueCount `json:"contents"`
+}
+
+type BulkCreateLinksRequest struct {
+	Links  []CreateLinkRequest `json:"links" binding:"required,min=1,max=500"`
+	Atomic bool                `json:"atomic"`
+}
+
+type BulkDeleteLinksRequest struct {
+	IDs    []uint `json:"ids" binding:"required,min=1,max=500"`
+	Atomic bool   `json:"atomic"`
+}
+
+type BulkItemResult struct {
+	Index   int           `json:"index"`
+	ID      uint          `json:"id,omitempty"`
+	Success bool          `json:"success"`
+	Link    *LinkResponse `json:"link,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+type BulkResponse struct {
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+type ImportLinksQuery struct {
+	DryRun bool `form:"dryRun"`
+}
+
+type ImportRowResult struct {
+	Row     int           `json:"row"`
+	Success bool          `json:"success"`
+	Link    *LinkResponse `json:"link,omitempty"`
+	Errors  []string      `json:"errors,omitempty"`
+}
+
+type ImportLinksResponse struct {
+	DryRun   bool              `json:"dryRun"`
+	Total    int               `json:"total"`
+	Valid    int               `json:"valid"`
+	Invalid  int               `json:"invalid"`
+	Imported int               `json:"imported"`
+	Rows     []ImportRowResult `json:"rows"`
+}
+
+type ExportLinksQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv json"`
+}
+
+type QRCodeQuery struct {
+	Format     string `form:"format" binding:"omitempty,oneof=png svg"`
+	Size       int    `form:"size" binding:"omitempty,min=64,max=2048"`
+	Level      string `form:"level" binding:"omitempty,oneof=L M Q H l m q h"`
+	Margin     *int   `form:"margin" binding:"omitempty,min=0,max=16"`
+	Foreground string `form:"fg"`
+	Background string `form:"bg"`
+	Logo       bool   `form:"logo"`
+}
+
+// StreamTokenResponse holds a short-lived token opening the user's event streams
+// when passed as the token query parameter
+type StreamTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
@@ -0,0 +1,59 @@
+package dtos
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestUpdateLinkRequestActiveWindow(t *testing.T) {
+	until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		body      string
+		wantSet   bool
+		wantUntil *time.Time
+	}{
+		{"omitted", `{"fallbackUrl": ""}`, false, nil},
+		{"null clears", `{"activeUntil": null}`, true, nil},
+		{"time sets", `{"activeUntil": "2030-01-02T03:04:05Z"}`, true, &until},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodPatch, "/api/v1/links/1", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var req UpdateLinkRequest
+			if err := binding.JSON.Bind(request, &req); err != nil {
+				t.Fatalf("Bind() error = %v", err)
+			}
+
+			if req.ActiveUntil.Set != tt.wantSet {
+				t.Errorf("ActiveUntil.Set = %v, want %v", req.ActiveUntil.Set, tt.wantSet)
+			}
+			got := req.ActiveUntil.Time
+			if (got == nil) != (tt.wantUntil == nil) || (got != nil && !got.Equal(*tt.wantUntil)) {
+				t.Errorf("ActiveUntil.Time = %v, want %v", got, tt.wantUntil)
+			}
+			if req.ActiveFrom.Set {
+				t.Errorf("ActiveFrom.Set = true for a body without activeFrom")
+			}
+		})
+	}
+}
+
+func TestUpdateLinkRequestRejectsInvalidTime(t *testing.T) {
+	request, err := http.NewRequest(http.MethodPatch, "/api/v1/links/1", strings.NewReader(`{"activeFrom": "tomorrow"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req UpdateLinkRequest
+	if err := binding.JSON.Bind(request, &req); err == nil {
+		t.Errorf("Bind() accepted activeFrom %q", "tomorrow")
+	}
+}
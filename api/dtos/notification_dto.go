@@ -0,0 +1,18 @@
+package dtos
+
+import "time"
+
+type NotificationQuery struct {
+	Unread bool   `form:"unread"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type NotificationResponse struct {
+	ID        uint       `json:"id"`
+	Kind      string     `json:"kind"`
+	Message   string     `json:"message"`
+	LinkID    *uint      `json:"linkId,omitempty"`
+	ReadAt    *time.Time `json:"readAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,22 @@
+package dtos
+
+import "time"
+
+type TagRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+type TagResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	LinkCount int       `json:"linkCount"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TagStatsResponse struct {
+	TagID       uint       `json:"tagId"`
+	Name        string     `json:"name"`
+	Links       int        `json:"links"`
+	Clicks      int        `json:"clicks"`
+	LastClicked *time.Time `json:"lastClicked,omitempty"`
+}
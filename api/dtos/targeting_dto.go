@@ -0,0 +1,32 @@
+package dtos
+
+// TargetingRuleRequest describes one rule. Each condition matches any of its
+// values; a rule matches when all of its non-empty conditions do. OS, device
+// and browser names are those the User-Agent parser reports.
+type TargetingRuleRequest struct {
+	Name           string   `json:"name,omitempty" binding:"max=100"`
+	OS             []string `json:"os,omitempty" binding:"omitempty,dive,max=50"`
+	Devices        []string `json:"devices,omitempty" binding:"omitempty,dive,max=50"`
+	Browsers       []string `json:"browsers,omitempty" binding:"omitempty,dive,max=50"`
+	Languages      []string `json:"languages,omitempty" binding:"omitempty,dive,min=2,max=35"`
+	Countries      []string `json:"countries,omitempty" binding:"omitempty,dive,len=2,alpha"`
+	DestinationURL string   `json:"destinationUrl" binding:"required,url"`
+}
+
+// SetTargetingRulesRequest replaces the rules of a link, in evaluation order.
+// An empty list removes all rules.
+type SetTargetingRulesRequest struct {
+	Rules []TargetingRuleRequest `json:"rules" binding:"max=50,dive"`
+}
+
+type TargetingRuleResponse struct {
+	ID             uint     `json:"id"`
+	Position       int      `json:"position"`
+	Name           string   `json:"name,omitempty"`
+	OS             []string `json:"os,omitempty"`
+	Devices        []string `json:"devices,omitempty"`
+	Browsers       []string `json:"browsers,omitempty"`
+	Languages      []string `json:"languages,omitempty"`
+	Countries      []string `json:"countries,omitempty"`
+	DestinationURL string   `json:"destinationUrl"`
+}
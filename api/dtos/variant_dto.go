@@ -0,0 +1,32 @@
+package dtos
+
+type CreateVariantRequest struct {
+	Name           string `json:"name" binding:"required,min=1,max=100"`
+	DestinationURL string `json:"destinationUrl" binding:"required,url"`
+	Weight         int    `json:"weight" binding:"required,min=1,max=1000"`
+}
+
+// UpdateVariantRequest changes the given fields of a variant; omitted fields are left untouched
+type UpdateVariantRequest struct {
+	Name           *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	DestinationURL *string `json:"destinationUrl,omitempty" binding:"omitempty,url"`
+	Weight         *int    `json:"weight,omitempty" binding:"omitempty,min=1,max=1000"`
+}
+
+type VariantResponse struct {
+	ID             uint    `json:"id"`
+	Name           string  `json:"name"`
+	DestinationURL string  `json:"destinationUrl"`
+	Weight         int     `json:"weight"`
+	Share          float64 `json:"share"`
+}
+
+// VariantStatsResponse counts the clicks sent to one variant. Deleted variants
+// keep their clicks but lose their name and destination.
+type VariantStatsResponse struct {
+	ID             uint   `json:"id"`
+	Name           string `json:"name,omitempty"`
+	DestinationURL string `json:"destinationUrl,omitempty"`
+	Deleted        bool   `json:"deleted,omitempty"`
+	Clicks         int64  `json:"clicks"`
+}
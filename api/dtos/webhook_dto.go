@@ -0,0 +1,57 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Events      []string `json:"events" binding:"required,min=1,dive,oneof=link.created link.updated link.deleted link.restored link.clicked link.expired"`
+	Description string   `json:"description,omitempty" binding:"max=200"`
+}
+
+// UpdateWebhookRequest changes the given fields of a webhook; omitted fields are left untouched
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url,omitempty" binding:"omitempty,url"`
+	Events      []string `json:"events,omitempty" binding:"omitempty,min=1,dive,oneof=link.created link.updated link.deleted link.restored link.clicked link.expired"`
+	Description *string  `json:"description,omitempty" binding:"omitempty,max=200"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// WebhookResponse describes a webhook. The signing secret is only included
+// when the webhook is created.
+type WebhookResponse struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type WebhookDeliveryQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded failed"`
+	Event  string `form:"event"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uint            `json:"id"`
+	EventID        string          `json:"eventId"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt,omitempty"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	ResponseBody   string          `json:"responseBody,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	DurationMs     int             `json:"durationMs"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	RedeliveryOf   *uint           `json:"redeliveryOf,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
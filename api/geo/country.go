@@ -0,0 +1,69 @@
+package geo
+
+import (
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// CountryLookup resolves IP addresses to ISO 3166-1 alpha-2 country codes
+type CountryLookup interface {
+	Country(ip net.IP) (string, error)
+}
+
+// Countries is used to locate visitors. It stays nil, and visitors have no
+// country, when no database is configured.
+var Countries CountryLookup
+
+// MaxMindDB reads countries from a MaxMind-format (.mmdb) database such as
+// GeoLite2-Country or DB-IP's free country database
+type MaxMindDB struct {
+	reader *maxminddb.Reader
+}
+
+// OpenMaxMindDB opens the database at path
+func OpenMaxMindDB(path string) (*MaxMindDB, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MaxMindDB{reader: reader}, nil
+}
+
+func (db *MaxMindDB) Country(ip net.IP) (string, error) {
+	var record struct {
+		Country struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+	}
+	if err := db.reader.Lookup(ip, &record); err != nil {
+		return "", err
+	}
+	return strings.ToLower(record.Country.ISOCode), nil
+}
+
+func (db *MaxMindDB) Close() error {
+	return db.reader.Close()
+}
+
+// StaticCountries is an in-memory CountryLookup keyed by IP string, for tests
+// and local setups
+type StaticCountries map[string]string
+
+func (s StaticCountries) Country(ip net.IP) (string, error) {
+	return s[ip.String()], nil
+}
+
+// CountryOf looks ip up in Countries, returning "" when it can't be located
+func CountryOf(ip string) string {
+	parsed := net.ParseIP(ip)
+	if Countries == nil || parsed == nil {
+		return ""
+	}
+	country, err := Countries.Country(parsed)
+	if err != nil {
+		return ""
+	}
+	return country
+}
@@ -0,0 +1,150 @@
+package healthcheck
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/caiohportella/blinky/safehttp"
+)
+
+const userAgent = "BlinkyBot/1.0 (+link health check)"
+
+// Result is the outcome of checking one destination
+type Result struct {
+	StatusCode int
+	Latency    time.Duration
+	// URLs the destination redirected to, in order
+	RedirectChain []string
+	// Err is set when no response was received at all
+	Err error
+}
+
+// Broken reports whether the destination looks dead: unreachable, gone or failing.
+// Other error statuses, such as 403 or 429, usually mean a bot was turned away
+// from a page that exists.
+func (r Result) Broken() bool {
+	return r.Err != nil || r.StatusCode == http.StatusNotFound || r.StatusCode == http.StatusGone || r.StatusCode >= 500
+}
+
+// hostSlot bounds the requests in flight to one host
+type hostSlot struct {
+	sem   chan struct{}
+	users int
+}
+
+// Checker requests destinations, never sending more than PerHost requests to the
+// same host at once
+type Checker struct {
+	Client  *http.Client
+	PerHost int
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+// NewChecker returns a Checker that refuses internal addresses unless allowPrivate is set
+func NewChecker(timeout time.Duration, perHost int, allowPrivate bool) *Checker {
+	return &Checker{
+		Client:  safehttp.NewClient(safehttp.Options{Timeout: timeout, MaxRedirects: 10, AllowPrivate: allowPrivate}),
+		PerHost: perHost,
+	}
+}
+
+// acquire waits for a free slot for host and returns the function releasing it
+func (c *Checker) acquire(ctx context.Context, host string) (func(), error) {
+	c.mu.Lock()
+	if c.hosts == nil {
+		c.hosts = map[string]*hostSlot{}
+	}
+	slot := c.hosts[host]
+	if slot == nil {
+		slot = &hostSlot{sem: make(chan struct{}, c.PerHost)}
+		c.hosts[host] = slot
+	}
+	slot.users++
+	c.mu.Unlock()
+
+	// Slots are dropped once nobody is using or waiting for them
+	done := func() {
+		c.mu.Lock()
+		slot.users--
+		if slot.users == 0 {
+			delete(c.hosts, host)
+		}
+		c.mu.Unlock()
+	}
+
+	select {
+	case slot.sem <- struct{}{}:
+		return func() {
+			<-slot.sem
+			done()
+		}, nil
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+}
+
+// Check requests target with HEAD, falling back to GET when HEAD fails or returns
+// an error status, since some servers don't implement HEAD properly
+func (c *Checker) Check(ctx context.Context, target string) Result {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return Result{Err: err}
+	}
+
+	release, err := c.acquire(ctx, parsed.Hostname())
+	if err != nil {
+		return Result{Err: err}
+	}
+	defer release()
+
+	result := c.request(ctx, http.MethodHead, target)
+	if result.Err != nil || result.StatusCode >= 400 {
+		if ctx.Err() != nil {
+			return result
+		}
+		result = c.request(ctx, http.MethodGet, target)
+	}
+	return result
+}
+
+func (c *Checker) request(ctx context.Context, method, target string) Result {
+	var result Result
+
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	// Record every hop while keeping the client's own redirect checks
+	client := *c.Client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		result.RedirectChain = append(result.RedirectChain, req.URL.String())
+		if c.Client.CheckRedirect != nil {
+			return c.Client.CheckRedirect(req, via)
+		}
+		return nil
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused
+	io.CopyN(io.Discard, resp.Body, 4<<10)
+	result.StatusCode = resp.StatusCode
+	return result
+}
@@ -0,0 +1,127 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newLocalChecker returns a checker allowed to reach httptest servers on loopback,
+// as with HEALTH_CHECK_ALLOW_PRIVATE
+func newLocalChecker(timeout time.Duration) *Checker {
+	return NewChecker(timeout, 2, true)
+}
+
+func TestCheckStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	})
+	mux.HandleFunc("/forbidden", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no bots", http.StatusForbidden)
+	})
+	mux.HandleFunc("/failing", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBroken bool
+	}{
+		{"/ok", http.StatusOK, false},
+		{"/missing", http.StatusNotFound, true},
+		{"/gone", http.StatusGone, true},
+		{"/forbidden", http.StatusForbidden, false},
+		{"/failing", http.StatusServiceUnavailable, true},
+		{"/no-head", http.StatusOK, false},
+	}
+	checker := newLocalChecker(time.Second)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result := checker.Check(context.Background(), server.URL+tt.path)
+			if result.Err != nil {
+				t.Fatalf("Check() error = %v", result.Err)
+			}
+			if result.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", result.StatusCode, tt.wantStatus)
+			}
+			if result.Broken() != tt.wantBroken {
+				t.Errorf("Broken() = %v, want %v", result.Broken(), tt.wantBroken)
+			}
+		})
+	}
+}
+
+func TestCheckRecordsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	result := newLocalChecker(time.Second).Check(context.Background(), server.URL+"/old")
+	if result.Err != nil {
+		t.Fatalf("Check() error = %v", result.Err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want %d", result.StatusCode, http.StatusOK)
+	}
+	want := []string{server.URL + "/moved", server.URL + "/new"}
+	if len(result.RedirectChain) != len(want) {
+		t.Fatalf("RedirectChain = %v, want %v", result.RedirectChain, want)
+	}
+	for i := range want {
+		if result.RedirectChain[i] != want[i] {
+			t.Errorf("RedirectChain[%d] = %q, want %q", i, result.RedirectChain[i], want[i])
+		}
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	result := newLocalChecker(50*time.Millisecond).Check(context.Background(), server.URL)
+	if result.Err == nil {
+		t.Fatalf("Check() of a server that never answers succeeded with status %d", result.StatusCode)
+	}
+	if !result.Broken() {
+		t.Error("Broken() = false for a timed out check")
+	}
+}
+
+func TestCheckRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	result := NewChecker(time.Second, 2, false).Check(context.Background(), server.URL)
+	if result.Err == nil {
+		t.Fatalf("Check() reached loopback without allowPrivate, status %d", result.StatusCode)
+	}
+}
@@ -0,0 +1,23 @@
+package initializers
+
+import (
+	"os"
+
+	"github.com/caiohportella/blinky/botfilter"
+)
+
+// BotClassifier tells visits from people apart from bots and link previews
+var BotClassifier *botfilter.Classifier
+
+// LoadBotClassifier sets BotClassifier up with the built-in User-Agent
+// signatures, or those in the file at BOT_SIGNATURES_PATH
+func LoadBotClassifier() error {
+	classifier := botfilter.NewClassifier()
+	if path := os.Getenv("BOT_SIGNATURES_PATH"); path != "" {
+		if err := classifier.LoadSignatures(path); err != nil {
+			return err
+		}
+	}
+	BotClassifier = classifier
+	return nil
+}
@@ -0,0 +1,86 @@
+package initializers
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShortURLBase is the base URL of short links on the default domain
+func ShortURLBase() string {
+	if base := os.Getenv("SHORT_URL_BASE"); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	return "http://localhost:3000"
+}
+
+// APIPublicURL is the base URL clients use to reach this API
+func APIPublicURL() string {
+	if base := os.Getenv("API_PUBLIC_URL"); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	return "http://localhost:8080"
+}
+
+// GetEnvInt reads an integer environment variable, falling back to def when unset or invalid
+func GetEnvInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s=%q, using %d", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+// GetEnvDuration reads a duration environment variable such as "90s" or "6h",
+// falling back to def when unset or invalid
+func GetEnvDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Warning: invalid %s=%q, using %s", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+// GetEnvBool reads a boolean environment variable such as "true" or "0",
+// falling back to def when unset or invalid
+func GetEnvBool(key string, def bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s=%q, using %t", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+// TrustedProxies lists the addresses or CIDRs of the proxies whose
+// X-Forwarded-For header is believed, such as the Next.js server resolving
+// links on the default short domain. It defaults to the loopback addresses.
+func TrustedProxies() []string {
+	value := os.Getenv("TRUSTED_PROXIES")
+	if value == "" {
+		return []string{"127.0.0.1", "::1"}
+	}
+	var proxies []string
+	for _, proxy := range strings.Split(value, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
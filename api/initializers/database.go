@@ -24,7 +24,8 @@ func ConnectToDatabase() {
 		os.Getenv("POSTGRES_SSLMODE"),
 	)
 
-	if DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{}); err != nil {
+	// TranslateError turns unique violations into gorm.ErrDuplicatedKey
+	if DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true}); err != nil {
 		log.Fatal("Failed to connect to database")
 	}
 
@@ -0,0 +1,15 @@
+package initializers
+
+import "github.com/caiohportella/blinky/pubsub"
+
+// EventHub carries live link events to the stream endpoints
+var EventHub *pubsub.Hub
+
+// LoadEventHub creates EventHub with STREAM_BUFFER_SIZE events of buffer per subscriber
+func LoadEventHub() {
+	buffer := GetEnvInt("STREAM_BUFFER_SIZE", 64)
+	if buffer < 1 {
+		buffer = 1
+	}
+	EventHub = pubsub.NewHub(buffer)
+}
@@ -0,0 +1,25 @@
+package initializers
+
+import (
+	"log"
+	"os"
+
+	"github.com/caiohportella/blinky/geo"
+)
+
+// LoadGeoIP opens the country database at GEOIP_DB_PATH. Without one, visitors
+// have no country and country targeting rules never match.
+func LoadGeoIP() error {
+	path := os.Getenv("GEOIP_DB_PATH")
+	if path == "" {
+		log.Println("GEOIP_DB_PATH not set, country targeting is disabled")
+		return nil
+	}
+
+	db, err := geo.OpenMaxMindDB(path)
+	if err != nil {
+		return err
+	}
+	geo.Countries = db
+	return nil
+}
@@ -0,0 +1,186 @@
+package initializers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/caiohportella/blinky/models"
+	"gorm.io/gorm"
+)
+
+// Migrate brings the database schema up to date with the models
+func Migrate() error {
+	if err := DB.AutoMigrate(&models.User{}, &models.Domain{}, &models.Folder{}, &models.Favicon{}, &models.Link{}, &models.Tag{}, &models.TargetingRule{}, &models.LinkVariant{}, &models.LinkVersion{}, &models.Click{}, &models.ClickRollup{}, &models.RollupWatermark{}, &models.DailyVisitor{}, &models.AuditEvent{}, &models.AbuseReport{}, &models.Notification{}, &models.Webhook{}, &models.WebhookDelivery{}); err != nil {
+		return err
+	}
+
+	// Codes generated before uniqueness ignored case may only differ in case,
+	// which would keep the index below from being built
+	if err := renameCaseDuplicateShortCodes(); err != nil {
+		return err
+	}
+	// Folder names weren't unique within a parent at first either
+	if err := renameDuplicateFolderNames(); err != nil {
+		return err
+	}
+
+	// Schema changes AutoMigrate can't express
+	for _, statement := range []string{
+		// Short codes are unique per domain ignoring case, trashed links included;
+		// the default domain has no id, so it is keyed as 0
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_links_domain_lower_short_code ON links (COALESCE(domain_id, 0), LOWER(short_code))",
+		// Folder names are unique within their parent; top-level folders are keyed as 0
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_folders_user_parent_name ON folders (user_id, COALESCE(parent_id, 0), name)",
+	} {
+		if err := DB.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameCaseDuplicateShortCodes gives a new code to every link whose short code
+// only differs in case from an older link's on the same domain. The oldest link
+// keeps its code; each rename is written to the audit log and the owner is notified.
+func renameCaseDuplicateShortCodes() error {
+	var duplicates []models.Link
+	err := DB.Unscoped().
+		Select("id", "short_code", "domain_id", "user_id").
+		Where(`EXISTS (SELECT 1 FROM links AS older WHERE older.id < links.id
+			AND COALESCE(older.domain_id, 0) = COALESCE(links.domain_id, 0)
+			AND LOWER(older.short_code) = LOWER(links.short_code))`).
+		Order("id").
+		Find(&duplicates).Error
+	if err != nil {
+		return err
+	}
+
+	for _, link := range duplicates {
+		var code string
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			if code, err = freeShortCode(tx, link); err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&models.Link{}).Where("id = ?", link.ID).Update("short_code", code).Error; err != nil {
+				return err
+			}
+
+			raw, err := json.Marshal(map[string]interface{}{
+				"shortCode": map[string]string{"before": link.ShortCode, "after": code},
+			})
+			if err != nil {
+				return err
+			}
+			changes := string(raw)
+			metadata := `{"reason":"case-insensitive duplicate"}`
+			event := models.AuditEvent{
+				Action:     models.AuditActionLinkUpdate,
+				TargetType: models.AuditTargetLink,
+				TargetID:   &link.ID,
+				Changes:    &changes,
+				Metadata:   &metadata,
+			}
+			if err := tx.Create(&event).Error; err != nil {
+				return err
+			}
+
+			return tx.Create(&models.Notification{
+				UserID:  link.UserID,
+				LinkID:  &link.ID,
+				Kind:    models.NotificationLinkCodeRenamed,
+				Message: fmt.Sprintf("The short code /%s was renamed to /%s because another link already used it with different case", link.ShortCode, code),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("renaming short code %q of link %d: %w", link.ShortCode, link.ID, err)
+		}
+		log.Printf("Renamed short code %q of link %d to %q, it only differed in case from an older link's", link.ShortCode, link.ID, code)
+	}
+	return nil
+}
+
+// freeShortCode returns the first of code-ID, code-ID-2, ... that no link on the
+// same domain uses, ignoring case
+func freeShortCode(tx *gorm.DB, link models.Link) (string, error) {
+	var domainKey uint
+	if link.DomainID != nil {
+		domainKey = *link.DomainID
+	}
+
+	base := fmt.Sprintf("%s-%d", link.ShortCode, link.ID)
+	for suffix := 1; ; suffix++ {
+		code := base
+		if suffix > 1 {
+			code = fmt.Sprintf("%s-%d", base, suffix)
+		}
+
+		var count int64
+		err := tx.Unscoped().Model(&models.Link{}).
+			Where("COALESCE(domain_id, 0) = ? AND LOWER(short_code) = LOWER(?)", domainKey, code).
+			Count(&count).Error
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return code, nil
+		}
+	}
+}
+
+// renameDuplicateFolderNames appends " (ID)" to every folder that has the same
+// name as an older folder of the same user and parent; the oldest keeps its name
+func renameDuplicateFolderNames() error {
+	var duplicates []models.Folder
+	err := DB.
+		Select("id", "name", "user_id", "parent_id").
+		Where(`EXISTS (SELECT 1 FROM folders AS older WHERE older.id < folders.id
+			AND older.user_id = folders.user_id
+			AND COALESCE(older.parent_id, 0) = COALESCE(folders.parent_id, 0)
+			AND older.name = folders.name)`).
+		Order("id").
+		Find(&duplicates).Error
+	if err != nil {
+		return err
+	}
+
+	for _, folder := range duplicates {
+		name, err := freeFolderName(folder)
+		if err != nil {
+			return err
+		}
+		if err := DB.Model(&models.Folder{}).Where("id = ?", folder.ID).Update("name", name).Error; err != nil {
+			return fmt.Errorf("renaming folder %d: %w", folder.ID, err)
+		}
+		log.Printf("Renamed folder %d from %q to %q, an older folder in the same parent had its name", folder.ID, folder.Name, name)
+	}
+	return nil
+}
+
+// freeFolderName returns the first of "name (ID)", "name (ID-2)", ... that no
+// other folder of the same user and parent uses
+func freeFolderName(folder models.Folder) (string, error) {
+	var parentKey uint
+	if folder.ParentID != nil {
+		parentKey = *folder.ParentID
+	}
+
+	for suffix := 1; ; suffix++ {
+		name := fmt.Sprintf("%s (%d)", folder.Name, folder.ID)
+		if suffix > 1 {
+			name = fmt.Sprintf("%s (%d-%d)", folder.Name, folder.ID, suffix)
+		}
+
+		var count int64
+		err := DB.Model(&models.Folder{}).
+			Where("user_id = ? AND COALESCE(parent_id, 0) = ? AND name = ?", folder.UserID, parentKey, name).
+			Count(&count).Error
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return name, nil
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package initializers
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/caiohportella/blinky/shortcode"
+)
+
+// CodeGenerator proposes codes for links created without a custom one
+var CodeGenerator shortcode.CodeGenerator
+
+// CodeValidator checks the custom codes users pick
+var CodeValidator *shortcode.Validator
+
+// LoadCodeGenerator sets CodeGenerator up with the strategy in SHORT_CODE_STRATEGY:
+// base62 (default), readable, sequential or words
+func LoadCodeGenerator() error {
+	length := GetEnvInt("SHORT_CODE_LENGTH", 7)
+	if length < 4 {
+		return fmt.Errorf("SHORT_CODE_LENGTH must be at least 4, got %d", length)
+	}
+
+	switch strategy := os.Getenv("SHORT_CODE_STRATEGY"); strategy {
+	case "", "base62":
+		CodeGenerator = shortcode.NewRandomGenerator(shortcode.Base62, length)
+	case "readable":
+		CodeGenerator = shortcode.NewRandomGenerator(shortcode.Readable, length)
+	case "sequential":
+		if err := DB.Exec("CREATE SEQUENCE IF NOT EXISTS short_code_seq").Error; err != nil {
+			return err
+		}
+		CodeGenerator = shortcode.NewSequenceGenerator(nextShortCodeNumber, shortcode.Readable, os.Getenv("SHORT_CODE_SALT"), length)
+	case "words":
+		CodeGenerator = shortcode.NewWordsGenerator(2)
+	default:
+		return fmt.Errorf("unknown SHORT_CODE_STRATEGY %q", strategy)
+	}
+	return nil
+}
+
+func nextShortCodeNumber() (uint64, error) {
+	var n uint64
+	err := DB.Raw("SELECT nextval('short_code_seq')").Scan(&n).Error
+	return n, err
+}
+
+// LoadCodeValidator sets CodeValidator up with the configured length limits and
+// the blocked words file at SHORT_CODE_BLOCKED_WORDS_PATH, if any
+func LoadCodeValidator() error {
+	minLength := GetEnvInt("CUSTOM_CODE_MIN_LENGTH", 3)
+	maxLength := GetEnvInt("CUSTOM_CODE_MAX_LENGTH", 50)
+	if minLength < 1 || maxLength < minLength {
+		return fmt.Errorf("invalid custom code length limits %d-%d", minLength, maxLength)
+	}
+
+	validator := shortcode.NewValidator(minLength, maxLength)
+	if path := os.Getenv("SHORT_CODE_BLOCKED_WORDS_PATH"); path != "" {
+		if err := validator.LoadBlockedWords(path); err != nil {
+			return err
+		}
+	}
+	CodeValidator = validator
+	return nil
+}
@@ -0,0 +1,53 @@
+package initializers
+
+import (
+	"log"
+	"net/url"
+	"os"
+	"sync/atomic"
+
+	"github.com/caiohportella/blinky/urlpolicy"
+)
+
+var urlPolicy atomic.Pointer[urlpolicy.Policy]
+
+// LoadURLPolicy (re)loads the destination URL policy from the files configured
+// in the environment. The previous policy stays in place if loading fails.
+func LoadURLPolicy() error {
+	var ownHosts []string
+	for _, base := range []string{ShortURLBase(), APIPublicURL()} {
+		if parsed, err := url.Parse(base); err == nil {
+			ownHosts = append(ownHosts, parsed.Hostname())
+		}
+	}
+
+	policy, err := urlpolicy.Load(urlpolicy.Config{
+		AllowlistPath: os.Getenv("URL_ALLOWLIST_PATH"),
+		BlocklistPath: os.Getenv("URL_BLOCKLIST_PATH"),
+		HashListPath:  os.Getenv("URL_HASH_LIST_PATH"),
+		OwnHosts:      ownHosts,
+		IsShortDomain: isCustomDomain,
+	})
+	if err != nil {
+		return err
+	}
+	urlPolicy.Store(policy)
+	return nil
+}
+
+// URLPolicy returns the current destination URL policy
+func URLPolicy() *urlpolicy.Policy {
+	return urlPolicy.Load()
+}
+
+// isCustomDomain reports whether host is a verified custom short domain. Pending
+// claims don't count, or anyone could block links to a site by claiming its host.
+// Lookup failures let the URL through rather than block every link.
+func isCustomDomain(host string) bool {
+	var count int64
+	if err := DB.Table("domains").Where("hostname = ? AND verified_at IS NOT NULL", host).Count(&count).Error; err != nil {
+		log.Printf("Failed to check custom domain %s: %v", host, err)
+		return false
+	}
+	return count > 0
+}
@@ -0,0 +1,25 @@
+package initializers
+
+import (
+	"os"
+
+	"github.com/caiohportella/blinky/useragent"
+)
+
+// UserAgentParser tells the browser, OS and device class of visitors
+var UserAgentParser *useragent.Parser
+
+// LoadUserAgentParser sets UserAgentParser up with the built-in definitions, or
+// those in the file at USER_AGENT_DEFINITIONS_PATH
+func LoadUserAgentParser() error {
+	if path := os.Getenv("USER_AGENT_DEFINITIONS_PATH"); path != "" {
+		parser, err := useragent.LoadParser(path)
+		if err != nil {
+			return err
+		}
+		UserAgentParser = parser
+		return nil
+	}
+	UserAgentParser = useragent.NewParser()
+	return nil
+}
@@ -0,0 +1,27 @@
+package initializers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/caiohportella/blinky/webhooks"
+)
+
+// WebhookDispatcher sends webhook deliveries
+var WebhookDispatcher *webhooks.Dispatcher
+
+// LoadWebhookDispatcher sets WebhookDispatcher up from the WEBHOOK_* settings
+func LoadWebhookDispatcher() error {
+	maxAttempts := GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 8)
+	if maxAttempts < 1 {
+		return fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS %d", maxAttempts)
+	}
+
+	WebhookDispatcher = webhooks.NewDispatcher(
+		GetEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		maxAttempts,
+		GetEnvDuration("WEBHOOK_RETRY_DELAY", 30*time.Second),
+		GetEnvBool("WEBHOOK_ALLOW_PRIVATE", false),
+	)
+	return nil
+}
@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+)
+
+const clickRetentionBatchSize = 5000
+
+// StartClickRetention deletes raw clicks older than CLICK_RETENTION_DAYS once
+// every rollup includes them. Rollups are kept. Clicks are kept forever when
+// CLICK_RETENTION_DAYS is 0, the default.
+func StartClickRetention(ctx context.Context) {
+	days := initializers.GetEnvInt("CLICK_RETENTION_DAYS", 0)
+	if days <= 0 {
+		return
+	}
+	interval := initializers.GetEnvDuration("CLICK_RETENTION_INTERVAL", time.Hour)
+	go runEvery(ctx, "click-retention", interval, func(ctx context.Context) error {
+		return purgeClicks(ctx, time.Duration(days)*24*time.Hour)
+	})
+}
+
+func purgeClicks(ctx context.Context, retention time.Duration) error {
+	// Never delete clicks a rollup may still need; with a missing watermark
+	// nothing has been rolled up yet
+	var watermarks []models.RollupWatermark
+	if err := initializers.DB.WithContext(ctx).Find(&watermarks).Error; err != nil {
+		return err
+	}
+	if len(watermarks) < len(rollupGranularities) {
+		return nil
+	}
+	cutoff := time.Now().Add(-retention).UTC().Truncate(24 * time.Hour)
+	for _, watermark := range watermarks {
+		if watermark.ProcessedUntil.Before(cutoff) {
+			cutoff = watermark.ProcessedUntil
+		}
+	}
+	total := int64(0)
+
+	// Delete in batches to keep transactions short
+	for ctx.Err() == nil {
+		result := initializers.DB.WithContext(ctx).
+			Where("id IN (?)", initializers.DB.Model(&models.Click{}).
+				Select("id").
+				Where("created_at < ?", cutoff).
+				Limit(clickRetentionBatchSize)).
+			Delete(&models.Click{})
+		if result.Error != nil {
+			return result.Error
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < clickRetentionBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("Deleted %d clicks older than %s", total, cutoff.Format(time.RFC3339))
+	}
+	return nil
+}
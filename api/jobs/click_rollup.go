@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/caiohportella/blinky/botfilter"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"gorm.io/gorm"
+)
+
+// rollupSettleDelay is how long after a bucket ends before it's considered
+// complete, leaving time for clicks still being recorded when it ended
+const rollupSettleDelay = time.Minute
+
+// referrerHostExpr extracts the lowercased host of a click's referrer
+const referrerHostExpr = `LOWER(substring(clicks.referrer from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/:?#]+)'))`
+
+// rollupGranularities are the bucket sizes clicks are rolled up into
+var rollupGranularities = []struct {
+	Name string
+	Size time.Duration
+}{
+	{models.RollupHour, time.Hour},
+	{models.RollupDay, 24 * time.Hour},
+}
+
+// rollupDimensions maps each rollup dimension to the click column it groups by
+var rollupDimensions = []struct {
+	Name string
+	Expr string
+}{
+	{models.RollupDimensionTotal, "''"},
+	{models.RollupDimensionReferrer, referrerHostExpr},
+	{models.RollupDimensionCountry, "clicks.country"},
+	{models.RollupDimensionDevice, "clicks.device"},
+	{models.RollupDimensionBrowser, "clicks.browser"},
+	{models.RollupDimensionOS, "clicks.os"},
+}
+
+// StartClickRollup keeps the hourly and daily click rollups read by the
+// analytics endpoints up to date
+func StartClickRollup(ctx context.Context) {
+	interval := initializers.GetEnvDuration("ROLLUP_INTERVAL", 5*time.Minute)
+	go runEvery(ctx, "click-rollup", interval, rollupClicks)
+}
+
+func rollupClicks(ctx context.Context) error {
+	for _, granularity := range rollupGranularities {
+		if err := rollupGranularity(ctx, granularity.Name, granularity.Size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollupGranularity computes the buckets from the granularity's watermark up to
+// now. Each complete bucket moves the watermark forward in the same transaction,
+// so an interrupted run picks up where it stopped. The bucket still in progress
+// is computed too but left after the watermark, to be computed again next time.
+func rollupGranularity(ctx context.Context, granularity string, size time.Duration) error {
+	db := initializers.DB.WithContext(ctx)
+
+	var watermark models.RollupWatermark
+	err := db.Where("granularity = ?", granularity).Take(&watermark).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Nothing rolled up yet; start from the first click
+		var first *time.Time
+		if err := db.Model(&models.Click{}).Select("MIN(created_at)").Scan(&first).Error; err != nil {
+			return err
+		}
+		if first == nil {
+			return nil
+		}
+		watermark = models.RollupWatermark{Granularity: granularity, ProcessedUntil: first.UTC().Truncate(size)}
+	} else if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for bucket := watermark.ProcessedUntil.UTC(); bucket.Before(now) && ctx.Err() == nil; bucket = bucket.Add(size) {
+		end := bucket.Add(size)
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := rollupBucket(tx, granularity, bucket, end); err != nil {
+				return err
+			}
+			if end.After(now.Add(-rollupSettleDelay)) {
+				return nil
+			}
+			watermark.ProcessedUntil = end
+			return tx.Save(&watermark).Error
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollupBucket (re)computes the rollups of the bucket [from, to) from human
+// clicks. Rows are replaced rather than added to, so a bucket can be computed
+// any number of times. Daily buckets also record each user's visitors of the day.
+func rollupBucket(tx *gorm.DB, granularity string, from, to time.Time) error {
+	for _, dimension := range rollupDimensions {
+		if err := tx.Exec(`INSERT INTO click_rollups (granularity, bucket_start, user_id, link_id, dimension, value, clicks, visitors)
+			SELECT ?, ?, links.user_id, clicks.link_id, ?, COALESCE(`+dimension.Expr+`, ''),
+				COUNT(*), COUNT(DISTINCT NULLIF(clicks.visitor_id, ''))
+			FROM clicks JOIN links ON links.id = clicks.link_id
+			WHERE clicks.created_at >= ? AND clicks.created_at < ? AND clicks.traffic = ?
+			GROUP BY links.user_id, clicks.link_id, 6
+			ON CONFLICT (granularity, bucket_start, link_id, dimension, value)
+			DO UPDATE SET clicks = EXCLUDED.clicks, visitors = EXCLUDED.visitors`,
+			granularity, from, dimension.Name, from, to, botfilter.Human).Error; err != nil {
+			return err
+		}
+	}
+
+	if granularity != models.RollupDay {
+		return nil
+	}
+	return tx.Exec(`INSERT INTO daily_visitors (user_id, day, visitor_id)
+		SELECT DISTINCT links.user_id, ?, clicks.visitor_id
+		FROM clicks JOIN links ON links.id = clicks.link_id
+		WHERE clicks.created_at >= ? AND clicks.created_at < ? AND clicks.traffic = ? AND clicks.visitor_id <> ''
+		ON CONFLICT DO NOTHING`,
+		from, from, to, botfilter.Human).Error
+}
@@ -0,0 +1,209 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/caiohportella/blinky/healthcheck"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"gorm.io/gorm"
+)
+
+const healthCheckBatchSize = 100
+
+// healthCheckConfig holds the health checker's settings
+type healthCheckConfig struct {
+	// Interval between checks of a link, healthy or broken
+	Interval time.Duration
+	// RetryDelay is the wait before the first retry of a failed check; it doubles
+	// with every further failure, up to Interval
+	RetryDelay time.Duration
+	// FailureThreshold is the number of failed checks in a row marking a link broken
+	FailureThreshold int
+	Concurrency      int
+}
+
+// StartHealthCheck periodically requests the destination of every enabled link
+// and records its status, latency and redirects. Links whose destination keeps
+// failing are marked broken and their owner is notified.
+func StartHealthCheck(ctx context.Context) {
+	config := healthCheckConfig{
+		Interval:         initializers.GetEnvDuration("HEALTH_CHECK_INTERVAL", 6*time.Hour),
+		RetryDelay:       initializers.GetEnvDuration("HEALTH_CHECK_RETRY_DELAY", time.Minute),
+		FailureThreshold: initializers.GetEnvInt("HEALTH_CHECK_FAILURE_THRESHOLD", 3),
+		Concurrency:      initializers.GetEnvInt("HEALTH_CHECK_CONCURRENCY", 10),
+	}
+	if config.Concurrency < 1 {
+		config.Concurrency = 1
+	}
+	checker := healthcheck.NewChecker(
+		initializers.GetEnvDuration("HEALTH_CHECK_TIMEOUT", 10*time.Second),
+		initializers.GetEnvInt("HEALTH_CHECK_PER_HOST", 2),
+		initializers.GetEnvBool("HEALTH_CHECK_ALLOW_PRIVATE", false),
+	)
+
+	poll := initializers.GetEnvDuration("HEALTH_CHECK_POLL_INTERVAL", time.Minute)
+	go runEvery(ctx, "health-check", poll, func(ctx context.Context) error {
+		return checkDueLinks(ctx, checker, config)
+	})
+}
+
+func checkDueLinks(ctx context.Context, checker *healthcheck.Checker, config healthCheckConfig) error {
+	for ctx.Err() == nil {
+		var links []models.Link
+		if err := initializers.DB.WithContext(ctx).
+			Where("disabled_at IS NULL AND (health_next_check_at IS NULL OR health_next_check_at <= ?)", time.Now()).
+			Order("health_next_check_at NULLS FIRST").
+			Limit(healthCheckBatchSize).
+			Find(&links).Error; err != nil {
+			return err
+		}
+
+		// Checks run in parallel; the checker keeps each host's share in check
+		jobs := make(chan models.Link)
+		errs := make(chan error, len(links))
+		var wg sync.WaitGroup
+		for i := 0; i < config.Concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for link := range jobs {
+					if err := checkLinkHealth(ctx, checker, config, link); err != nil {
+						errs <- fmt.Errorf("link %d: %w", link.ID, err)
+					}
+				}
+			}()
+		}
+		for _, link := range links {
+			jobs <- link
+		}
+		close(jobs)
+		wg.Wait()
+		close(errs)
+
+		// A link whose result wasn't stored keeps its health_next_check_at and would
+		// be picked again right away, so stop until the next run
+		failed := 0
+		for err := range errs {
+			log.Printf("Failed to store health check: %v", err)
+			failed++
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d health checks in the batch could not be stored", failed, len(links))
+		}
+
+		if len(links) < healthCheckBatchSize {
+			break
+		}
+	}
+	return nil
+}
+
+// retryDelay is how long to wait before checking a link again after its nth
+// failed check in a row
+func retryDelay(config healthCheckConfig, failures int) time.Duration {
+	delay := config.RetryDelay
+	for i := 1; i < failures && delay < config.Interval; i++ {
+		delay *= 2
+	}
+	if delay > config.Interval {
+		return config.Interval
+	}
+	return delay
+}
+
+// checkLinkHealth checks one link's destination and stores the result
+func checkLinkHealth(ctx context.Context, checker *healthcheck.Checker, config healthCheckConfig, link models.Link) error {
+	result := checker.Check(ctx, link.OriginalURL)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	updates, notification, err := healthUpdates(config, link, result, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// Only store the result if the destination hasn't changed in the meantime. The
+	// link's updated_at is left alone since its owner didn't change anything.
+	return initializers.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Link{}).
+			Where("id = ? AND original_url = ?", link.ID, link.OriginalURL).
+			UpdateColumns(updates)
+		if result.Error != nil || result.RowsAffected == 0 || notification == nil {
+			return result.Error
+		}
+		return tx.Create(notification).Error
+	})
+}
+
+// healthUpdates turns the result of a check of link at now into the link columns
+// to update, and the notification to send its owner if the link just broke or
+// recovered
+func healthUpdates(config healthCheckConfig, link models.Link, result healthcheck.Result, now time.Time) (map[string]interface{}, *models.Notification, error) {
+	updates := map[string]interface{}{
+		"health_checked_at":     now,
+		"health_status_code":    result.StatusCode,
+		"health_latency_ms":     int(result.Latency.Milliseconds()),
+		"health_redirect_chain": nil,
+		"health_error":          "",
+	}
+	if len(result.RedirectChain) > 0 {
+		chain, err := json.Marshal(result.RedirectChain)
+		if err != nil {
+			return nil, nil, err
+		}
+		updates["health_redirect_chain"] = string(chain)
+	}
+	if result.Err != nil {
+		updates["health_error"] = result.Err.Error()
+	}
+
+	var notification *models.Notification
+	if result.Broken() {
+		failures := link.HealthFailures + 1
+		updates["health_failures"] = failures
+		if failures < config.FailureThreshold {
+			updates["health_next_check_at"] = now.Add(retryDelay(config, failures))
+		} else {
+			updates["health_next_check_at"] = now.Add(config.Interval)
+			if link.BrokenAt == nil {
+				updates["broken_at"] = now
+				notification = &models.Notification{
+					UserID:  link.UserID,
+					LinkID:  &link.ID,
+					Kind:    models.NotificationLinkBroken,
+					Message: fmt.Sprintf("The destination of /%s is broken (%s)", link.ShortCode, describeFailure(result)),
+				}
+			}
+		}
+	} else {
+		updates["health_failures"] = 0
+		updates["health_next_check_at"] = now.Add(config.Interval)
+		if link.BrokenAt != nil {
+			updates["broken_at"] = nil
+			notification = &models.Notification{
+				UserID:  link.UserID,
+				LinkID:  &link.ID,
+				Kind:    models.NotificationLinkRecovered,
+				Message: fmt.Sprintf("The destination of /%s is reachable again", link.ShortCode),
+			}
+		}
+	}
+
+	return updates, notification, nil
+}
+
+// describeFailure summarizes why a check failed for notifications
+func describeFailure(result healthcheck.Result) string {
+	if result.Err != nil {
+		return "unreachable"
+	}
+	return "HTTP " + strconv.Itoa(result.StatusCode)
+}
@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/caiohportella/blinky/healthcheck"
+	"github.com/caiohportella/blinky/models"
+)
+
+// applyHealthUpdates copies the columns healthUpdates sets that decide what the
+// next check does, as storing them would
+func applyHealthUpdates(link *models.Link, updates map[string]interface{}) {
+	link.HealthFailures = updates["health_failures"].(int)
+	if brokenAt, ok := updates["broken_at"]; ok {
+		if brokenAt == nil {
+			link.BrokenAt = nil
+		} else {
+			at := brokenAt.(time.Time)
+			link.BrokenAt = &at
+		}
+	}
+}
+
+func TestHealthCheckNotifiesWhenBrokenAndRecovered(t *testing.T) {
+	var status atomic.Int64
+	status.Store(http.StatusInternalServerError)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	// Loopback is only reachable with HEALTH_CHECK_ALLOW_PRIVATE
+	checker := healthcheck.NewChecker(time.Second, 2, true)
+	config := healthCheckConfig{Interval: 6 * time.Hour, RetryDelay: time.Minute, FailureThreshold: 3}
+	link := models.Link{UserID: 3, ShortCode: "launch", OriginalURL: server.URL}
+	link.ID = 7
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	check := func() (map[string]interface{}, *models.Notification) {
+		t.Helper()
+		updates, notification, err := healthUpdates(config, link, checker.Check(context.Background(), link.OriginalURL), now)
+		if err != nil {
+			t.Fatalf("healthUpdates() error = %v", err)
+		}
+		applyHealthUpdates(&link, updates)
+		return updates, notification
+	}
+
+	// Failures below the threshold are retried sooner, without telling anyone
+	for failures, wantDelay := range []time.Duration{time.Minute, 2 * time.Minute} {
+		updates, notification := check()
+		if notification != nil {
+			t.Fatalf("failure %d sent notification %q", failures+1, notification.Message)
+		}
+		if next := updates["health_next_check_at"].(time.Time); !next.Equal(now.Add(wantDelay)) {
+			t.Errorf("failure %d: next check at %v, want %v", failures+1, next, now.Add(wantDelay))
+		}
+	}
+
+	updates, notification := check()
+	if notification == nil || notification.Kind != models.NotificationLinkBroken {
+		t.Fatalf("reaching the threshold sent %+v, want a %s notification", notification, models.NotificationLinkBroken)
+	}
+	if want := "The destination of /launch is broken (HTTP 500)"; notification.Message != want {
+		t.Errorf("Message = %q, want %q", notification.Message, want)
+	}
+	if notification.UserID != 3 || notification.LinkID == nil || *notification.LinkID != 7 {
+		t.Errorf("notification addressed to user %d about link %v, want user 3 and link 7", notification.UserID, notification.LinkID)
+	}
+	if updates["broken_at"] != now {
+		t.Errorf("broken_at = %v, want %v", updates["broken_at"], now)
+	}
+
+	// A link already marked broken isn't announced again
+	if _, notification := check(); notification != nil {
+		t.Errorf("still broken link sent notification %q", notification.Message)
+	}
+
+	status.Store(http.StatusOK)
+	updates, notification = check()
+	if notification == nil || notification.Kind != models.NotificationLinkRecovered {
+		t.Fatalf("recovery sent %+v, want a %s notification", notification, models.NotificationLinkRecovered)
+	}
+	if brokenAt, ok := updates["broken_at"]; !ok || brokenAt != nil {
+		t.Errorf("broken_at = %v, want it cleared", brokenAt)
+	}
+	if updates["health_failures"] != 0 {
+		t.Errorf("health_failures = %v, want 0", updates["health_failures"])
+	}
+}
+
+func TestHealthCheckReportsUnreachableDestinations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	target := server.URL
+	server.Close()
+
+	checker := healthcheck.NewChecker(time.Second, 2, true)
+	config := healthCheckConfig{Interval: time.Hour, RetryDelay: time.Minute, FailureThreshold: 1}
+	link := models.Link{UserID: 1, ShortCode: "dead", OriginalURL: target}
+	link.ID = 1
+
+	updates, notification, err := healthUpdates(config, link, checker.Check(context.Background(), target), time.Now())
+	if err != nil {
+		t.Fatalf("healthUpdates() error = %v", err)
+	}
+	if updates["health_error"] == "" {
+		t.Error("health_error is empty for a refused connection")
+	}
+	if notification == nil || notification.Message != "The destination of /dead is broken (unreachable)" {
+		t.Errorf("notification = %+v, want the link reported unreachable", notification)
+	}
+}
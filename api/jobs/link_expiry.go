@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/webhooks"
+	"gorm.io/gorm"
+)
+
+const linkExpiryBatchSize = 100
+
+// StartLinkExpiry sends the link.expired webhook event for links whose
+// activation window has ended
+func StartLinkExpiry(ctx context.Context) {
+	interval := initializers.GetEnvDuration("LINK_EXPIRY_SCAN_INTERVAL", time.Minute)
+	go runEvery(ctx, "link-expiry", interval, announceExpiredLinks)
+}
+
+func announceExpiredLinks(ctx context.Context) error {
+	for ctx.Err() == nil {
+		var links []models.Link
+		if err := initializers.DB.WithContext(ctx).
+			Where("active_until <= ? AND expired_event_at IS NULL", time.Now()).
+			Order("id").
+			Limit(linkExpiryBatchSize).
+			Find(&links).Error; err != nil {
+			return err
+		}
+
+		for _, link := range links {
+			err := initializers.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				// The expiry may have been moved since the link was loaded
+				result := tx.Model(&models.Link{}).
+					Where("id = ? AND active_until <= ? AND expired_event_at IS NULL", link.ID, time.Now()).
+					UpdateColumn("expired_event_at", time.Now())
+				if result.Error != nil || result.RowsAffected == 0 {
+					return result.Error
+				}
+				return webhooks.Enqueue(tx, link.UserID, models.WebhookEventLinkExpired, webhooks.NewLinkData(link))
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(links) < linkExpiryBatchSize {
+			break
+		}
+	}
+	return nil
+}
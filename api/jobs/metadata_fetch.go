@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/metadata"
+	"github.com/caiohportella/blinky/models"
+	"gorm.io/gorm/clause"
+)
+
+const metadataFetchBatchSize = 20
+
+// StartMetadataFetch fills in the title, description, preview image and favicon
+// of links whose destination hasn't been fetched yet
+func StartMetadataFetch(ctx context.Context) {
+	interval := initializers.GetEnvDuration("METADATA_FETCH_INTERVAL", 30*time.Second)
+	fetcher := metadata.NewFetcher(initializers.GetEnvDuration("METADATA_FETCH_TIMEOUT", 10*time.Second))
+	go runEvery(ctx, "metadata-fetch", interval, func(ctx context.Context) error {
+		return fetchPendingMetadata(ctx, fetcher)
+	})
+}
+
+func fetchPendingMetadata(ctx context.Context, fetcher *metadata.Fetcher) error {
+	for ctx.Err() == nil {
+		var links []models.Link
+		if err := initializers.DB.WithContext(ctx).
+			Where("metadata_fetched_at IS NULL").
+			Order("id").
+			Limit(metadataFetchBatchSize).
+			Find(&links).Error; err != nil {
+			return err
+		}
+
+		for _, link := range links {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := fetchLinkMetadata(ctx, fetcher, link); err != nil {
+				return err
+			}
+		}
+
+		if len(links) < metadataFetchBatchSize {
+			break
+		}
+	}
+	return nil
+}
+
+// fetchLinkMetadata fetches one link's destination and stores what was found. Failed
+// fetches are marked as done too, so unreachable destinations aren't retried forever;
+// changing the destination URL queues the link again.
+func fetchLinkMetadata(ctx context.Context, fetcher *metadata.Fetcher, link models.Link) error {
+	updates := map[string]interface{}{"metadata_fetched_at": time.Now()}
+
+	page, icon, err := fetcher.Fetch(ctx, link.OriginalURL)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("Failed to fetch metadata for link %d: %v", link.ID, err)
+	} else {
+		updates["title"] = page.Title
+		updates["description"] = page.Description
+		updates["image_url"] = page.ImageURL
+		if icon != nil {
+			faviconID, err := storeFavicon(ctx, icon)
+			if err != nil {
+				return err
+			}
+			updates["favicon_id"] = faviconID
+		}
+	}
+
+	// Only store the result if the destination hasn't changed in the meantime
+	return initializers.DB.WithContext(ctx).Model(&models.Link{}).
+		Where("id = ? AND original_url = ? AND metadata_fetched_at IS NULL", link.ID, link.OriginalURL).
+		Updates(updates).Error
+}
+
+// storeFavicon saves icon unless an identical one already exists, returning its ID
+func storeFavicon(ctx context.Context, icon *metadata.Icon) (uint, error) {
+	sum := sha256.Sum256(icon.Data)
+	favicon := models.Favicon{
+		Hash:        hex.EncodeToString(sum[:]),
+		ContentType: icon.ContentType,
+		Data:        icon.Data,
+	}
+
+	db := initializers.DB.WithContext(ctx)
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&favicon).Error; err != nil {
+		return 0, err
+	}
+	err := db.Select("id").Where("hash = ?", favicon.Hash).First(&favicon).Error
+	return favicon.ID, err
+}
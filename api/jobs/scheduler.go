@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runEvery calls fn right away and then on every tick of interval until ctx is done.
+// Errors are logged so that one failed run doesn't stop the job.
+func runEvery(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Job %s started (every %s)", name, interval)
+	for {
+		if err := fn(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Job %s failed: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Job %s stopped", name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+)
+
+const trashPurgeBatchSize = 500
+
+// TrashRetention is how long deleted links stay in the trash before being purged
+func TrashRetention() time.Duration {
+	return time.Duration(initializers.GetEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour
+}
+
+// StartTrashPurge permanently removes links that have been in the trash for longer
+// than TrashRetention, freeing their short codes
+func StartTrashPurge(ctx context.Context) {
+	interval := initializers.GetEnvDuration("TRASH_PURGE_INTERVAL", time.Hour)
+	go runEvery(ctx, "trash-purge", interval, purgeTrash)
+}
+
+func purgeTrash(ctx context.Context) error {
+	cutoff := time.Now().Add(-TrashRetention())
+	total := int64(0)
+
+	// Delete in batches to keep transactions short
+	for ctx.Err() == nil {
+		result := initializers.DB.WithContext(ctx).Unscoped().
+			Where("id IN (?)", initializers.DB.Unscoped().Model(&models.Link{}).
+				Select("id").
+				Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+				Limit(trashPurgeBatchSize)).
+			Delete(&models.Link{})
+		if result.Error != nil {
+			return result.Error
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < trashPurgeBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("Purged %d links from the trash", total)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/urlpolicy"
+	"gorm.io/gorm"
+)
+
+const urlRescreenBatchSize = 500
+
+// StartURLRescreen periodically reloads the URL policy lists and runs existing
+// links through them again, with their fallback, variant and targeting rule
+// destinations. Links with any destination that now fails are disabled; links
+// disabled by an earlier screening whose destinations all pass again are re-enabled.
+func StartURLRescreen(ctx context.Context) {
+	interval := initializers.GetEnvDuration("URL_RESCREEN_INTERVAL", 24*time.Hour)
+	go runEvery(ctx, "url-rescreen", interval, rescreenLinks)
+}
+
+// screenDestinations checks every destination a visitor of link may be sent to
+// and returns the rejection of the first one that fails, or nil
+func screenDestinations(policy *urlpolicy.Policy, link models.Link) *urlpolicy.Rejection {
+	if rejection := policy.Check(link.OriginalURL); rejection != nil {
+		return rejection
+	}
+	if link.FallbackURL != "" {
+		if rejection := policy.Check(link.FallbackURL); rejection != nil {
+			return &urlpolicy.Rejection{Rule: rejection.Rule, Reason: "Fallback: " + rejection.Reason}
+		}
+	}
+	for _, variant := range link.Variants {
+		if rejection := policy.Check(variant.DestinationURL); rejection != nil {
+			return &urlpolicy.Rejection{Rule: rejection.Rule, Reason: "Variant: " + rejection.Reason}
+		}
+	}
+	for _, rule := range link.TargetingRules {
+		if rejection := policy.Check(rule.DestinationURL); rejection != nil {
+			return &urlpolicy.Rejection{Rule: rejection.Rule, Reason: "Targeting rule: " + rejection.Reason}
+		}
+	}
+	return nil
+}
+
+func rescreenLinks(ctx context.Context) error {
+	if err := initializers.LoadURLPolicy(); err != nil {
+		log.Printf("Failed to reload URL policy, screening with the previous one: %v", err)
+	}
+	policy := initializers.URLPolicy()
+
+	disabled, enabled := 0, 0
+	var links []models.Link
+	result := initializers.DB.WithContext(ctx).
+		Select("id", "original_url", "fallback_url", "disabled_at", "disabled_by").
+		Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "link_id", "destination_url")
+		}).
+		Preload("TargetingRules", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "link_id", "destination_url")
+		}).
+		Where("disabled_at IS NULL OR disabled_by = ?", models.LinkDisabledByScreening).
+		FindInBatches(&links, urlRescreenBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, link := range links {
+				rejection := screenDestinations(policy, link)
+				switch {
+				case rejection != nil && link.DisabledAt == nil:
+					if err := initializers.DB.WithContext(ctx).Model(&models.Link{}).
+						Where("id = ? AND disabled_at IS NULL", link.ID).
+						Updates(map[string]interface{}{
+							"disabled_at":     time.Now(),
+							"disabled_reason": rejection.Reason,
+							"disabled_by":     models.LinkDisabledByScreening,
+						}).Error; err != nil {
+						return err
+					}
+					disabled++
+				case rejection == nil && link.DisabledAt != nil:
+					if err := initializers.DB.WithContext(ctx).Model(&models.Link{}).
+						Where("id = ? AND disabled_by = ?", link.ID, models.LinkDisabledByScreening).
+						Updates(map[string]interface{}{
+							"disabled_at":     nil,
+							"disabled_reason": "",
+							"disabled_by":     "",
+						}).Error; err != nil {
+						return err
+					}
+					enabled++
+				}
+			}
+			return ctx.Err()
+		})
+
+	if disabled > 0 || enabled > 0 {
+		log.Printf("URL rescreen disabled %d links and re-enabled %d", disabled, enabled)
+	}
+	if result.Error != nil && ctx.Err() == nil {
+		return result.Error
+	}
+	return nil
+}
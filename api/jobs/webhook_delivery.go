@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	webhookDeliveryBatchSize = 50
+
+	// webhookDeliveryLease keeps claimed deliveries from being picked up again
+	// while they are being sent
+	webhookDeliveryLease = 5 * time.Minute
+)
+
+// StartWebhookDelivery sends queued webhook deliveries as they fall due. The
+// queue lives in the database, so pending deliveries survive restarts.
+func StartWebhookDelivery(ctx context.Context) {
+	interval := initializers.GetEnvDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second)
+	concurrency := initializers.GetEnvInt("WEBHOOK_CONCURRENCY", 5)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	go runEvery(ctx, "webhook-delivery", interval, func(ctx context.Context) error {
+		return sendDueDeliveries(ctx, concurrency)
+	})
+}
+
+// claimDueDeliveries leases a batch of due deliveries. Rows claimed by another
+// instance are skipped.
+func claimDueDeliveries(ctx context.Context) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := initializers.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(webhookDeliveryBatchSize).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(webhookDeliveryLease)).Error
+	})
+	return deliveries, err
+}
+
+func sendDueDeliveries(ctx context.Context, concurrency int) error {
+	for ctx.Err() == nil {
+		deliveries, err := claimDueDeliveries(ctx)
+		if err != nil {
+			return err
+		}
+
+		webhookIDs := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			webhookIDs = append(webhookIDs, delivery.WebhookID)
+		}
+		var hooks []models.Webhook
+		if len(webhookIDs) > 0 {
+			if err := initializers.DB.WithContext(ctx).Find(&hooks, webhookIDs).Error; err != nil {
+				return err
+			}
+		}
+		hooksByID := map[uint]models.Webhook{}
+		for _, hook := range hooks {
+			hooksByID[hook.ID] = hook
+		}
+
+		jobs := make(chan models.WebhookDelivery)
+		errs := make(chan error, len(deliveries))
+		var wg sync.WaitGroup
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for delivery := range jobs {
+					if err := sendDelivery(ctx, hooksByID[delivery.WebhookID], delivery); err != nil {
+						errs <- err
+					}
+				}
+			}()
+		}
+		for _, delivery := range deliveries {
+			jobs <- delivery
+		}
+		close(jobs)
+		wg.Wait()
+		close(errs)
+		if err := <-errs; err != nil {
+			return err
+		}
+
+		if len(deliveries) < webhookDeliveryBatchSize {
+			break
+		}
+	}
+	return nil
+}
+
+// sendDelivery sends one delivery. Deliveries of deactivated webhooks are given up.
+func sendDelivery(ctx context.Context, hook models.Webhook, delivery models.WebhookDelivery) error {
+	if !hook.Active {
+		return initializers.DB.WithContext(ctx).Model(&delivery).Updates(map[string]interface{}{
+			"status":          models.WebhookDeliveryFailed,
+			"error":           "Webhook is inactive",
+			"next_attempt_at": nil,
+		}).Error
+	}
+	return initializers.WebhookDispatcher.Send(ctx, initializers.DB.WithContext(ctx), hook, &delivery)
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/caiohportella/blinky/controllers"
 	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/jobs"
 	"github.com/caiohportella/blinky/middlewares"
-	"github.com/caiohportella/blinky/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,14 +23,54 @@ func init() {
 
 	// Auto-migrate on startup
 	log.Println("Running database migrations...")
-	if err := initializers.DB.AutoMigrate(&models.User{}, &models.Link{}); err != nil {
+	if err := initializers.Migrate(); err != nil {
 		log.Fatal("Failed to migrate database: ", err)
 	}
 	log.Println("Database migrations completed!")
+
+	if err := initializers.LoadURLPolicy(); err != nil {
+		log.Fatal("Failed to load URL policy: ", err)
+	}
+	if err := initializers.LoadCodeGenerator(); err != nil {
+		log.Fatal("Failed to set up short code generation: ", err)
+	}
+	if err := initializers.LoadCodeValidator(); err != nil {
+		log.Fatal("Failed to set up custom code validation: ", err)
+	}
+	if err := initializers.LoadGeoIP(); err != nil {
+		log.Fatal("Failed to open GeoIP database: ", err)
+	}
+	if err := initializers.LoadWebhookDispatcher(); err != nil {
+		log.Fatal("Failed to set up webhooks: ", err)
+	}
+	if err := initializers.LoadBotClassifier(); err != nil {
+		log.Fatal("Failed to load bot signatures: ", err)
+	}
+	if err := initializers.LoadUserAgentParser(); err != nil {
+		log.Fatal("Failed to load user agent definitions: ", err)
+	}
+	initializers.LoadEventHub()
 }
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background jobs stop when ctx is cancelled
+	jobs.StartTrashPurge(ctx)
+	jobs.StartMetadataFetch(ctx)
+	jobs.StartURLRescreen(ctx)
+	jobs.StartHealthCheck(ctx)
+	jobs.StartWebhookDelivery(ctx)
+	jobs.StartLinkExpiry(ctx)
+	jobs.StartClickRollup(ctx)
+	jobs.StartClickRetention(ctx)
+
 	router := gin.Default()
+	// Visitors of the default short domain reach the API through the frontend
+	if err := router.SetTrustedProxies(initializers.TrustedProxies()); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
 	router.Use(middlewares.CORSMiddleware())
 
 	router.GET("/health", func(c *gin.Context) {
@@ -33,6 +79,11 @@ func main() {
 
 	// Public redirect endpoint (no auth required)
 	router.GET("/r/:shortCode", controllers.RedirectLink)
+	router.POST("/r/:shortCode/report", controllers.ReportLink)
+	router.GET("/favicons/:id", controllers.GetFavicon)
+
+	// Custom short domains serve their links at the root, e.g. go.example.com/launch
+	router.NoRoute(controllers.RedirectByHost)
 
 	v1 := router.Group("/api/v1")
 	{
@@ -41,17 +92,120 @@ func main() {
 			users.POST("", controllers.SignUpWithToken)
 			users.POST("/login", controllers.LoginWithToken)
 			users.GET("/me", middlewares.RequireAuthWithToken, controllers.GetCurrentUser)
+			users.POST("/me/revoke-tokens", middlewares.RequireAuthWithToken, controllers.RevokeTokens)
 		}
 
+		// Event streams also accept a stream token, which browsers can pass in the URL
+		v1.GET("/links/stream", middlewares.RequireStreamToken, controllers.StreamLinks)
+		v1.GET("/links/:id/stream", middlewares.RequireStreamToken, controllers.StreamLink)
+
 		links := v1.Group("/links")
 		links.Use(middlewares.RequireAuthWithToken)
 		{
 			links.GET("", controllers.GetLinks)
 			links.POST("", controllers.CreateLink)
+			links.POST("/bulk", controllers.BulkCreateLinks)
+			links.POST("/bulk-delete", controllers.BulkDeleteLinks)
+			links.POST("/import", controllers.ImportLinks)
+			links.GET("/export", controllers.ExportLinks)
+			links.GET("/trash", controllers.GetTrashedLinks)
+			links.POST("/stream-token", controllers.CreateStreamToken)
+			links.PATCH("/:id", controllers.UpdateLink)
 			links.DELETE("/:id", controllers.DeleteLink)
+			links.DELETE("/:id/permanent", controllers.PurgeLink)
+			links.POST("/:id/restore", controllers.RestoreLink)
 			links.GET("/:id/stats", controllers.GetLinkStats)
+			links.GET("/:id/history", controllers.GetLinkHistory)
+			links.POST("/:id/history/:version/restore", controllers.RestoreLinkVersion)
+			links.GET("/:id/qr", controllers.GetLinkQRCode)
+			links.GET("/:id/targeting", controllers.GetTargetingRules)
+			links.PUT("/:id/targeting", controllers.SetTargetingRules)
+			links.GET("/:id/variants", controllers.GetVariants)
+			links.POST("/:id/variants", controllers.CreateVariant)
+			links.PATCH("/:id/variants/:variantId", controllers.UpdateVariant)
+			links.DELETE("/:id/variants/:variantId", controllers.DeleteVariant)
+		}
+
+		domains := v1.Group("/domains")
+		domains.Use(middlewares.RequireAuthWithToken)
+		{
+			domains.GET("", controllers.GetDomains)
+			domains.POST("", controllers.CreateDomain)
+			domains.POST("/:id/verify", controllers.VerifyDomain)
+			domains.DELETE("/:id", controllers.DeleteDomain)
+		}
+
+		tags := v1.Group("/tags")
+		tags.Use(middlewares.RequireAuthWithToken)
+		{
+			tags.GET("", controllers.GetTags)
+			tags.POST("", controllers.CreateTag)
+			tags.PATCH("/:id", controllers.UpdateTag)
+			tags.DELETE("/:id", controllers.DeleteTag)
+			tags.GET("/:id/stats", controllers.GetTagStats)
+		}
+
+		folders := v1.Group("/folders")
+		folders.Use(middlewares.RequireAuthWithToken)
+		{
+			folders.GET("", controllers.GetFolders)
+			folders.POST("", controllers.CreateFolder)
+			folders.PATCH("/:id", controllers.UpdateFolder)
+			folders.DELETE("/:id", controllers.DeleteFolder)
+		}
+
+		hooks := v1.Group("/webhooks")
+		hooks.Use(middlewares.RequireAuthWithToken)
+		{
+			hooks.GET("", controllers.GetWebhooks)
+			hooks.POST("", controllers.CreateWebhook)
+			hooks.PATCH("/:id", controllers.UpdateWebhook)
+			hooks.DELETE("/:id", controllers.DeleteWebhook)
+			hooks.POST("/:id/ping", controllers.PingWebhook)
+			hooks.GET("/:id/deliveries", controllers.GetWebhookDeliveries)
+			hooks.POST("/:id/deliveries/:deliveryId/redeliver", controllers.RedeliverWebhookDelivery)
+		}
+
+		notifications := v1.Group("/notifications")
+		notifications.Use(middlewares.RequireAuthWithToken)
+		{
+			notifications.GET("", controllers.GetNotifications)
+			notifications.POST("/:id/read", controllers.MarkNotificationRead)
+		}
+
+		v1.GET("/analytics/overview", middlewares.RequireAuthWithToken, controllers.GetAnalyticsOverview)
+
+		v1.GET("/audit", middlewares.RequireAuthWithToken, controllers.GetAuditEvents)
+
+		admin := v1.Group("/admin")
+		admin.Use(middlewares.RequireAuthWithToken, middlewares.RequireAdmin)
+		{
+			admin.GET("/reports", controllers.GetAbuseReports)
+			admin.POST("/reports/:id/resolve", controllers.ResolveAbuseReport)
+			admin.POST("/links/:id/disable", controllers.DisableLink)
+			admin.POST("/links/:id/enable", controllers.EnableLink)
+			admin.POST("/users/:id/unflag", controllers.UnflagUser)
+			admin.PUT("/users/:id/role", controllers.UpdateUserRole)
 		}
 	}
 
-	router.Run(":8080")
+	server := &http.Server{Addr: ":8080", Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server: ", err)
+		}
+	}()
+
+	// Wait for a shutdown signal, then let in-flight requests finish
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	// Shutdown waits for open requests, so end the event streams first
+	initializers.EventHub.Close()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server forced to shut down: ", err)
+	}
 }
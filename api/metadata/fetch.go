@@ -0,0 +1,247 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/caiohportella/blinky/safehttp"
+	"golang.org/x/net/html"
+)
+
+const (
+	userAgent = "BlinkyBot/1.0 (+link preview)"
+
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+)
+
+var ErrNotHTML = errors.New("destination is not an HTML page")
+
+// Page is what a destination page says about itself
+type Page struct {
+	Title       string
+	Description string
+	ImageURL    string
+	IconURLs    []string
+}
+
+// Icon is a downloaded favicon
+type Icon struct {
+	ContentType string
+	Data        []byte
+}
+
+// Fetcher downloads pages and favicons with bounded time and size
+type Fetcher struct {
+	Client       *http.Client
+	MaxPageBytes int64
+	MaxIconBytes int64
+}
+
+// NewFetcher returns a Fetcher that refuses internal addresses
+func NewFetcher(timeout time.Duration) *Fetcher {
+	return &Fetcher{
+		Client:       safehttp.NewClient(safehttp.Options{Timeout: timeout, MaxRedirects: 5}),
+		MaxPageBytes: 1 << 20,
+		MaxIconBytes: 100 << 10,
+	}
+}
+
+func (f *Fetcher) get(ctx context.Context, target, accept string) (*http.Response, error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", parsed.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", accept)
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// FetchPage downloads pageURL and extracts its title, description, preview image and icons.
+// Only the first MaxPageBytes of the document are read.
+func (f *Fetcher) FetchPage(ctx context.Context, pageURL string) (*Page, error) {
+	resp, err := f.get(ctx, pageURL, "text/html,application/xhtml+xml")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+
+	doc, err := html.Parse(io.LimitReader(resp.Body, f.MaxPageBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	// Relative URLs resolve against the final URL, after redirects
+	page := parseHead(doc, resp.Request.URL)
+	return page, nil
+}
+
+// FetchIcon downloads an icon, rejecting anything that isn't an image or is larger than MaxIconBytes
+func (f *Fetcher) FetchIcon(ctx context.Context, iconURL string) (*Icon, error) {
+	resp, err := f.get(ctx, iconURL, "image/*")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.ContentLength > f.MaxIconBytes {
+		return nil, fmt.Errorf("icon is larger than %d bytes", f.MaxIconBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.MaxIconBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > f.MaxIconBytes {
+		return nil, fmt.Errorf("icon is larger than %d bytes", f.MaxIconBytes)
+	}
+
+	// Trust the bytes over the header, which is often wrong for .ico files
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		headerType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if !strings.HasPrefix(headerType, "image/") {
+			return nil, fmt.Errorf("icon has content type %q", contentType)
+		}
+		contentType = headerType
+	}
+	return &Icon{ContentType: contentType, Data: data}, nil
+}
+
+// Fetch gets the page metadata and its favicon. The favicon is best-effort:
+// icons that fail to download are skipped and the page is still returned.
+func (f *Fetcher) Fetch(ctx context.Context, pageURL string) (*Page, *Icon, error) {
+	page, err := f.FetchPage(ctx, pageURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, iconURL := range page.IconURLs {
+		if icon, err := f.FetchIcon(ctx, iconURL); err == nil {
+			return page, icon, nil
+		}
+	}
+	return page, nil, nil
+}
+
+// parseHead walks the document collecting metadata from <title>, <meta> and <link> tags
+func parseHead(doc *html.Node, base *url.URL) *Page {
+	page := &Page{}
+	var title, description string
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "title":
+				if title == "" && n.FirstChild != nil && n.FirstChild.Type == html.TextNode {
+					title = n.FirstChild.Data
+				}
+			case "meta":
+				key := strings.ToLower(attr(n, "property"))
+				if key == "" {
+					key = strings.ToLower(attr(n, "name"))
+				}
+				content := attr(n, "content")
+				switch key {
+				case "og:title":
+					page.Title = content
+				case "og:description":
+					page.Description = content
+				case "description":
+					description = content
+				case "og:image", "og:image:url":
+					if page.ImageURL == "" {
+						page.ImageURL = resolve(base, content)
+					}
+				}
+			case "link":
+				for _, rel := range strings.Fields(strings.ToLower(attr(n, "rel"))) {
+					if rel == "icon" || rel == "apple-touch-icon" {
+						if href := resolve(base, attr(n, "href")); href != "" {
+							page.IconURLs = append(page.IconURLs, href)
+						}
+						break
+					}
+				}
+			case "body":
+				// Everything we look for lives in <head>
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	// Fall back to the plain HTML title and description
+	if page.Title == "" {
+		page.Title = title
+	}
+	if page.Description == "" {
+		page.Description = description
+	}
+	page.Title = truncate(strings.Join(strings.Fields(page.Title), " "), maxTitleLength)
+	page.Description = truncate(strings.Join(strings.Fields(page.Description), " "), maxDescriptionLength)
+
+	// Browsers look for /favicon.ico when a page doesn't declare an icon
+	page.IconURLs = append(page.IconURLs, resolve(base, "/favicon.ico"))
+	return page
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// resolve makes ref absolute against base, returning "" for non-HTTP results
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	parsed, err := base.Parse(ref)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+	return parsed.String()
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
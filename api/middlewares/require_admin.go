@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin only lets admins through. It must run after RequireAuthWithToken.
+func RequireAdmin(c *gin.Context) {
+	userInterface, exists := c.Get("user")
+	user, ok := userInterface.(models.User)
+	if !exists || !ok {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		c.Abort()
+		return
+	}
+
+	if user.Role != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Admin access required",
+		})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
@@ -52,6 +52,16 @@ func RequireAuthWithCookie(c *gin.Context) {
 			return
 		}
 
+		// Stream tokens only open event streams
+		if audience, _ := claims["aud"].(string); audience == StreamTokenAudience {
+			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Unauthorized - Invalid token",
+			})
+			c.Abort()
+			return
+		}
+
 		// Extract user ID from claims and convert to uint
 		userID := uint(claims["sub"].(float64))
 
@@ -65,6 +75,16 @@ func RequireAuthWithCookie(c *gin.Context) {
 			return
 		}
 
+		// Tokens issued before the user revoked their tokens are rejected
+		if version, _ := claims["ver"].(float64); int(version) != user.TokenVersion {
+			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Unauthorized - Token revoked",
+			})
+			c.Abort()
+			return
+		}
+
 		c.Set("user", user)
 		c.Next()
 	} else {
@@ -131,6 +151,16 @@ func RequireAuthWithToken(c *gin.Context) {
 			return
 		}
 
+		// Stream tokens only open event streams
+		if audience, _ := claims["aud"].(string); audience == StreamTokenAudience {
+			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Unauthorized - Invalid token",
+			})
+			c.Abort()
+			return
+		}
+
 		// Extract user ID from claims and convert to uint
 		userID := uint(claims["sub"].(float64))
 
@@ -144,6 +174,16 @@ func RequireAuthWithToken(c *gin.Context) {
 			return
 		}
 
+		// Tokens issued before the user revoked their tokens are rejected
+		if version, _ := claims["ver"].(float64); int(version) != user.TokenVersion {
+			c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+				Success: false,
+				Error:   "Unauthorized - Token revoked",
+			})
+			c.Abort()
+			return
+		}
+
 		// Attach user to context
 		c.Set("user", user)
 
@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/caiohportella/blinky/dtos"
+	"github.com/caiohportella/blinky/initializers"
+	"github.com/caiohportella/blinky/models"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// StreamTokenAudience is the audience of the short-lived tokens that open event
+// streams. Other routes refuse them.
+const StreamTokenAudience = "stream"
+
+// RequireStreamToken authenticates event stream requests by the stream token in
+// the token query parameter, since a browser's EventSource can't send headers.
+// Requests without one need the usual Authorization header.
+func RequireStreamToken(c *gin.Context) {
+	tokenString := c.Query("token")
+	if tokenString == "" {
+		RequireAuthWithToken(c)
+		return
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(os.Getenv("SECRET_KEY")), nil
+	}, jwt.WithAudience(StreamTokenAudience), jwt.WithExpirationRequired())
+
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized - Invalid token",
+		})
+		c.Abort()
+		return
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	subject, _ := claims["sub"].(float64)
+	if !ok || subject == 0 {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized - Invalid token claims",
+		})
+		c.Abort()
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, uint(subject)).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized - User not found",
+		})
+		c.Abort()
+		return
+	}
+
+	// Stream tokens minted before the user revoked their tokens are rejected too
+	if version, _ := claims["ver"].(float64); int(version) != user.TokenVersion {
+		c.JSON(http.StatusUnauthorized, dtos.ErrorResponse{
+			Success: false,
+			Error:   "Unauthorized - Token revoked",
+		})
+		c.Abort()
+		return
+	}
+
+	c.Set("user", user)
+	c.Next()
+}
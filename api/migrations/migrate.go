@@ -4,7 +4,6 @@ import (
 	"log"
 
 	"github.com/caiohportella/blinky/initializers"
-	"github.com/caiohportella/blinky/models"
 )
 
 func init() {
@@ -14,7 +13,7 @@ func init() {
 
 func main() {
 	log.Println("Migrating database...")
-	err := initializers.DB.AutoMigrate(&models.User{}, &models.Link{})
+	err := initializers.Migrate()
 	if err != nil {
 		log.Fatal("Failed to migrate database")
 	}
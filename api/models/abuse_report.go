@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	AbuseReasonPhishing = "phishing"
+	AbuseReasonMalware  = "malware"
+	AbuseReasonSpam     = "spam"
+	AbuseReasonIllegal  = "illegal"
+	AbuseReasonOther    = "other"
+)
+
+const (
+	AbuseReportPending   = "pending"
+	AbuseReportActioned  = "actioned"
+	AbuseReportDismissed = "dismissed"
+)
+
+// AbuseReport is a public complaint about a short link, waiting for or
+// resolved by an admin review
+type AbuseReport struct {
+	ID             uint      `gorm:"primaryKey"`
+	CreatedAt      time.Time `gorm:"index"`
+	UpdatedAt      time.Time
+	LinkID         uint   `gorm:"not null;index"`
+	Link           Link   `gorm:"foreignKey:LinkID;constraint:OnDelete:CASCADE"`
+	Reason         string `gorm:"not null"`
+	Details        string
+	ReporterEmail  string
+	ReporterIP     string
+	Status         string `gorm:"not null;default:pending;index"`
+	ReviewedByID   *uint
+	ReviewedAt     *time.Time
+	ResolutionNote string
+}
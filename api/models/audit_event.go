@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AuditActionSignup       = "user.signup"
+	AuditActionLoginSuccess = "user.login_success"
+	AuditActionLoginFailure = "user.login_failure"
+	AuditActionTokenRevoke  = "user.token_revoke"
+	AuditActionRoleChange   = "user.role_change"
+	AuditActionUserFlag     = "user.flag"
+	AuditActionUserUnflag   = "user.unflag"
+	AuditActionLinkCreate   = "link.create"
+	AuditActionLinkUpdate   = "link.update"
+	AuditActionLinkDelete   = "link.delete"
+	AuditActionLinkRestore  = "link.restore"
+	AuditActionLinkPurge    = "link.purge"
+	AuditActionLinkDisable  = "link.disable"
+	AuditActionLinkEnable   = "link.enable"
+	AuditActionLinkRollback = "link.rollback"
+)
+
+const (
+	AuditTargetUser = "user"
+	AuditTargetLink = "link"
+)
+
+var ErrAuditEventImmutable = errors.New("audit events are append-only")
+
+// AuditEvent is an append-only record of a security- or link-relevant action.
+// It intentionally doesn't embed gorm.Model: events are never updated or deleted.
+type AuditEvent struct {
+	ID         uint      `gorm:"primaryKey"`
+	CreatedAt  time.Time `gorm:"index"`
+	Action     string    `gorm:"index;not null"`
+	ActorID    *uint     `gorm:"index"`
+	TargetType string    `gorm:"index:idx_audit_events_target"`
+	TargetID   *uint     `gorm:"index:idx_audit_events_target"`
+	IP         string
+	UserAgent  string
+	Changes    *string `gorm:"type:jsonb"`
+	Metadata   *string `gorm:"type:jsonb"`
+}
+
+func (AuditEvent) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditEventImmutable
+}
+
+func (AuditEvent) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditEventImmutable
+}
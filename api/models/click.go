@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Click is a single visit of a short link, recorded on redirect. VisitorID tells
+// visitors apart without storing their IP address. Visits from bots and link
+// preview fetchers are recorded too, but told apart by Traffic.
+type Click struct {
+	ID              uint      `gorm:"primaryKey"`
+	CreatedAt       time.Time `gorm:"index"`
+	LinkID          uint      `gorm:"not null;index"`
+	Link            Link      `gorm:"foreignKey:LinkID;constraint:OnDelete:CASCADE"`
+	LinkVersion     int       `gorm:"not null;default:0"`
+	TargetingRuleID *uint
+	VariantID       *uint `gorm:"index"`
+	Destination     string
+	Country         string
+	OS              string
+	OSVersion       string
+	Browser         string
+	BrowserVersion  string
+	Device          string
+	Language        string
+	Referrer        string
+	VisitorID       string
+	UTMSource       string
+	UTMMedium       string
+	UTMCampaign     string
+	UTMTerm         string
+	UTMContent      string
+
+	// Traffic is human, bot or preview; only human clicks count towards Link.Clicks
+	Traffic string `gorm:"not null;default:human;index"`
+}
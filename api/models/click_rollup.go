@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Rollup bucket sizes
+const (
+	RollupHour = "hour"
+	RollupDay  = "day"
+)
+
+// Rollup dimensions. The total of each bucket is stored with an empty dimension and value.
+const (
+	RollupDimensionTotal    = ""
+	RollupDimensionReferrer = "referrer"
+	RollupDimensionCountry  = "country"
+	RollupDimensionDevice   = "device"
+	RollupDimensionBrowser  = "browser"
+	RollupDimensionOS       = "os"
+)
+
+// ClickRollup counts the clicks a link received in one time bucket, overall or
+// for one value of a dimension, e.g. the clicks from "de" for the country
+// dimension. Visitors are unique within the bucket and link.
+type ClickRollup struct {
+	ID          uint      `gorm:"primaryKey"`
+	Granularity string    `gorm:"not null;uniqueIndex:idx_click_rollups_bucket,priority:1;index:idx_click_rollups_user,priority:2"`
+	BucketStart time.Time `gorm:"not null;uniqueIndex:idx_click_rollups_bucket,priority:2;index:idx_click_rollups_user,priority:4"`
+	UserID      uint      `gorm:"not null;index:idx_click_rollups_user,priority:1"`
+	LinkID      uint      `gorm:"not null;uniqueIndex:idx_click_rollups_bucket,priority:3"`
+	Link        Link      `gorm:"foreignKey:LinkID;constraint:OnDelete:CASCADE"`
+	Dimension   string    `gorm:"not null;uniqueIndex:idx_click_rollups_bucket,priority:4;index:idx_click_rollups_user,priority:3"`
+	Value       string    `gorm:"not null;uniqueIndex:idx_click_rollups_bucket,priority:5"`
+	Clicks      int64     `gorm:"not null"`
+	Visitors    int64     `gorm:"not null"`
+}
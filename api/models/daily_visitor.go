@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// DailyVisitor records that a visitor clicked at least one of a user's links on a
+// UTC day. Unlike the per-bucket visitor counts of ClickRollup, these can be
+// counted distinctly over any range of days.
+type DailyVisitor struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Day       time.Time `gorm:"primaryKey"`
+	VisitorID string    `gorm:"primaryKey"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Domain is a custom short domain registered by a user. Links can only use it
+// once ownership has been proven through a DNS TXT record. Several users may
+// claim the same hostname; the first to verify it keeps it.
+type Domain struct {
+	ID                uint `gorm:"primaryKey"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Hostname          string `gorm:"not null;index;uniqueIndex:idx_domains_user_hostname,priority:2;uniqueIndex:idx_domains_verified_hostname,where:verified_at IS NOT NULL"`
+	UserID            uint   `gorm:"not null;index;uniqueIndex:idx_domains_user_hostname,priority:1"`
+	User              User   `gorm:"foreignKey:UserID"`
+	VerificationToken string `gorm:"not null"`
+	VerifiedAt        *time.Time
+}
+
+func (d Domain) Verified() bool {
+	return d.VerifiedAt != nil
+}
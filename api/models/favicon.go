@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Favicon is an icon downloaded from a link destination. Identical icons are
+// stored once and shared by every link pointing at the same site.
+type Favicon struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	Hash        string `gorm:"not null;uniqueIndex"`
+	ContentType string `gorm:"not null"`
+	Data        []byte `gorm:"not null"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Folder groups links into an optional hierarchy. Like tags, folders are
+// hard-deleted; their links and subfolders move up to the parent folder.
+type Folder struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Name      string   `gorm:"not null"`
+	UserID    uint     `gorm:"not null;index"`
+	User      User     `gorm:"foreignKey:UserID"`
+	ParentID  *uint    `gorm:"index"`
+	Parent    *Folder  `gorm:"foreignKey:ParentID"`
+	Children  []Folder `gorm:"foreignKey:ParentID"`
+}
@@ -1,13 +1,104 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sources of a link being disabled
+const (
+	LinkDisabledByScreening = "screening"
+	LinkDisabledByAdmin     = "admin"
+)
+
+// Link statuses, computed from the activation window
+const (
+	LinkStatusScheduled = "scheduled"
+	LinkStatusActive    = "active"
+	LinkStatusEnded     = "ended"
+)
+
+// Which value wins when the visitor's query and the destination both set a parameter
+const (
+	QueryPrecedenceDestination = "destination"
+	QueryPrecedenceIncoming    = "incoming"
+)
 
 type Link struct {
 	gorm.Model
-	ShortCode   string `gorm:"unique"`
-	OriginalURL string
-	Clicks      int `gorm:"default:0"`
-	Favicon     string
-	User        User `gorm:"foreignKey:UserID"`
-	UserID      uint
+	ShortCode     string  `gorm:"not null"`
+	DomainID      *uint   `gorm:"index"`
+	Domain        *Domain `gorm:"foreignKey:DomainID"`
+	OriginalURL   string
+	Clicks        int `gorm:"default:0;index"`
+	LastClickedAt *time.Time
+	ActiveFrom    *time.Time `gorm:"index"`
+	ActiveUntil   *time.Time `gorm:"index"`
+	FallbackURL   string
+	User          User `gorm:"foreignKey:UserID"`
+	UserID        uint
+	FolderID      *uint   `gorm:"index"`
+	Folder        *Folder `gorm:"foreignKey:FolderID;constraint:OnDelete:SET NULL"`
+	Tags          []Tag   `gorm:"many2many:link_tags;constraint:OnDelete:CASCADE"`
+
+	// Visits from bots and link preview fetchers, which Clicks leaves out
+	BotClicks int `gorm:"not null;default:0"`
+
+	// Number of the link's current entry in its version history
+	Version int `gorm:"not null;default:0"`
+
+	// Disabled links no longer redirect
+	DisabledAt     *time.Time `gorm:"index"`
+	DisabledReason string
+	DisabledBy     string
+
+	// Visitors matching a rule are sent to its destination instead
+	TargetingRules []TargetingRule `gorm:"constraint:OnDelete:CASCADE"`
+
+	// Everyone else rotates between the variants, if there are any
+	Variants       []LinkVariant `gorm:"constraint:OnDelete:CASCADE"`
+	StickyRotation string
+
+	// Query parameters added to the destination on redirect
+	QueryPassthrough bool
+	QueryPrecedence  string
+	UTMSource        string
+	UTMMedium        string
+	UTMCampaign      string
+	UTMTerm          string
+	UTMContent       string
+
+	// Destination metadata, filled in in the background by the metadata fetcher
+	Title             string
+	Description       string
+	ImageURL          string
+	FaviconID         *uint      `gorm:"index"`
+	Favicon           *Favicon   `gorm:"foreignKey:FaviconID;constraint:OnDelete:SET NULL"`
+	MetadataFetchedAt *time.Time `gorm:"index"`
+
+	// Destination health, updated in the background by the health checker
+	HealthCheckedAt     *time.Time
+	HealthNextCheckAt   *time.Time `gorm:"index"`
+	HealthStatusCode    int
+	HealthLatencyMs     int
+	HealthRedirectChain *string `gorm:"type:jsonb"`
+	HealthError         string
+	HealthFailures      int
+	BrokenAt            *time.Time `gorm:"index"`
+
+	// Set once the link.expired event has been sent for the current expiry
+	ExpiredEventAt *time.Time
+}
+
+// Status tells whether the link's activation window has started, is open or has ended at now
+func (l Link) Status(now time.Time) string {
+	switch {
+	case l.ActiveFrom != nil && now.Before(*l.ActiveFrom):
+		return LinkStatusScheduled
+	case l.ActiveUntil != nil && !now.Before(*l.ActiveUntil):
+		return LinkStatusEnded
+	default:
+		return LinkStatusActive
+	}
 }
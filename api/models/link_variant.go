@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Ways a visitor keeps seeing the same variant of a rotating link
+const (
+	StickyRotationNone   = ""
+	StickyRotationCookie = "cookie"
+	StickyRotationIP     = "ip"
+)
+
+// LinkVariant is one of the destinations a link rotates between. Visitors are
+// spread across a link's variants in proportion to their weights.
+type LinkVariant struct {
+	ID             uint `gorm:"primaryKey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	LinkID         uint   `gorm:"not null;index"`
+	Name           string `gorm:"not null"`
+	DestinationURL string `gorm:"not null"`
+	Weight         int    `gorm:"not null;default:1"`
+}
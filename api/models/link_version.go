@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// LinkVersion is a snapshot of a link's destination and settings, written each
+// time they change. Versions are numbered from 1 per link and never updated.
+type LinkVersion struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	LinkID    uint  `gorm:"not null;uniqueIndex:idx_link_versions_link_version,priority:1"`
+	Link      Link  `gorm:"foreignKey:LinkID;constraint:OnDelete:CASCADE"`
+	Version   int   `gorm:"not null;uniqueIndex:idx_link_versions_link_version,priority:2"`
+	AuthorID  *uint `gorm:"index"`
+	Author    *User `gorm:"foreignKey:AuthorID;constraint:OnDelete:SET NULL"`
+	// Set when the version was created by restoring an older one
+	RestoredFrom *int
+
+	OriginalURL      string
+	ActiveFrom       *time.Time
+	ActiveUntil      *time.Time
+	FallbackURL      string
+	StickyRotation   string
+	QueryPassthrough bool
+	QueryPrecedence  string
+	UTMSource        string
+	UTMMedium        string
+	UTMCampaign      string
+	UTMTerm          string
+	UTMContent       string
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	NotificationLinkBroken      = "link.broken"
+	NotificationLinkRecovered   = "link.recovered"
+	NotificationLinkCodeRenamed = "link.code_renamed"
+)
+
+// Notification is a message for a user about one of their links, shown in the dashboard
+type Notification struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"index"`
+	UserID    uint      `gorm:"not null;index"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	LinkID    *uint     `gorm:"index"`
+	Link      *Link     `gorm:"foreignKey:LinkID;constraint:OnDelete:CASCADE"`
+	Kind      string    `gorm:"not null"`
+	Message   string    `gorm:"not null"`
+	ReadAt    *time.Time
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// RollupWatermark records up to when the clicks have been rolled up for one
+// granularity. Buckets before ProcessedUntil are complete and never recomputed.
+type RollupWatermark struct {
+	Granularity    string    `gorm:"primaryKey"`
+	ProcessedUntil time.Time `gorm:"not null"`
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Tag is a user-defined label for links. Tags are hard-deleted so that a
+// name can be reused right away; that's why gorm.Model isn't embedded.
+type Tag struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Name      string `gorm:"not null;uniqueIndex:idx_tags_user_name"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_tags_user_name"`
+	User      User   `gorm:"foreignKey:UserID"`
+	Links     []Link `gorm:"many2many:link_tags;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// TargetingRule sends visitors of a link that match all of its non-empty
+// conditions to DestinationURL. A link's rules are evaluated by Position and
+// the first match wins. Conditions are comma-separated lowercase lists.
+type TargetingRule struct {
+	ID             uint `gorm:"primaryKey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	LinkID         uint `gorm:"not null;index:idx_targeting_rules_link_position,priority:1"`
+	Position       int  `gorm:"not null;index:idx_targeting_rules_link_position,priority:2"`
+	Name           string
+	OS             string
+	Devices        string
+	Browsers       string
+	Languages      string
+	Countries      string
+	DestinationURL string `gorm:"not null"`
+}
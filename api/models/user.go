@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 const (
 	RoleUser  = "USER"
@@ -14,4 +18,13 @@ type User struct {
 	Password string
 	Role     string `gorm:"default:USER;not null"`
 	Links    []Link `gorm:"foreignKey:UserID"`
+
+	// Flagged accounts can't create links until an admin clears the flag. Only
+	// links disabled after FlagClearedAt count towards flagging the account again.
+	FlaggedAt     *time.Time `gorm:"index"`
+	FlagReason    string
+	FlagClearedAt *time.Time
+
+	// Bumped to revoke every token issued before; tokens carry the version they were issued at
+	TokenVersion int `gorm:"not null;default:0"`
 }
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Events webhooks can subscribe to
+const (
+	WebhookEventLinkCreated = "link.created"
+	// Sent when a link's settings are edited or rolled back to an earlier version
+	WebhookEventLinkUpdated = "link.updated"
+	WebhookEventLinkDeleted = "link.deleted"
+	// Sent when a link is taken back out of the trash
+	WebhookEventLinkRestored = "link.restored"
+	WebhookEventLinkClicked  = "link.clicked"
+	WebhookEventLinkExpired  = "link.expired"
+	// Sent on demand to test an endpoint, whatever the webhook subscribes to
+	WebhookEventPing = "ping"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is an endpoint of a user's that receives signed POSTs for the link
+// events it subscribes to. Events is a comma-separated list.
+type Webhook struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uint   `gorm:"not null;index"`
+	User        User   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	URL         string `gorm:"not null"`
+	Secret      string `gorm:"not null"`
+	Events      string `gorm:"not null"`
+	Description string
+	Active      bool `gorm:"not null;default:true"`
+}
+
+// Subscribes reports whether the webhook wants event
+func (w Webhook) Subscribes(event string) bool {
+	for _, subscribed := range strings.Split(w.Events, ",") {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for, or sent to, a webhook. Pending
+// deliveries are retried with exponential backoff until they succeed or run
+// out of attempts.
+type WebhookDelivery struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"index"`
+	UpdatedAt time.Time
+	WebhookID uint    `gorm:"not null;index"`
+	Webhook   Webhook `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE"`
+	// EventID is shared by every delivery of the same event, redeliveries included,
+	// so receivers can skip duplicates
+	EventID       string `gorm:"not null;index"`
+	Event         string `gorm:"not null"`
+	Payload       string `gorm:"type:jsonb;not null"`
+	RedeliveryOf  *uint
+	Status        string     `gorm:"not null;default:pending"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt *time.Time `gorm:"index"`
+	LastAttemptAt *time.Time
+	// Outcome of the last attempt
+	ResponseStatus int
+	ResponseBody   string
+	Error          string
+	DurationMs     int
+	DeliveredAt    *time.Time
+}
@@ -0,0 +1,104 @@
+package pubsub
+
+import "sync"
+
+// Event is a message for the subscribers of one user
+type Event struct {
+	Type string
+	// LinkID is the link the event is about, if any
+	LinkID uint
+	Data   interface{}
+}
+
+// Subscription receives a user's events on C until it is closed. C is closed
+// when the subscription ends, whether by Unsubscribe, by falling too far behind
+// or by the hub closing.
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	userID uint
+	linkID uint
+}
+
+// Hub fans events out to in-process subscribers. Publishing never blocks: a
+// subscriber whose buffer is full is dropped, and is expected to reconnect.
+type Hub struct {
+	buffer int
+
+	mu     sync.Mutex
+	subs   map[uint]map[*Subscription]struct{}
+	closed bool
+}
+
+// NewHub returns a hub giving each subscriber a buffer of size events
+func NewHub(buffer int) *Hub {
+	return &Hub{buffer: buffer, subs: map[uint]map[*Subscription]struct{}{}}
+}
+
+// Subscribe returns a subscription to the events of userID, only those about
+// linkID if it isn't 0. It returns nil once the hub is closed.
+func (h *Hub) Subscribe(userID, linkID uint) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+
+	c := make(chan Event, h.buffer)
+	sub := &Subscription{C: c, c: c, userID: userID, linkID: linkID}
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[*Subscription]struct{}{}
+	}
+	h.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+// remove ends sub; h.mu must be held
+func (h *Hub) remove(sub *Subscription) {
+	subs := h.subs[sub.userID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subs, sub.userID)
+	}
+	close(sub.c)
+}
+
+// Unsubscribe ends sub. It is safe to call more than once.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+// Publish sends event to the subscribers of userID
+func (h *Hub) Publish(userID uint, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs[userID] {
+		if sub.linkID != 0 && sub.linkID != event.LinkID {
+			continue
+		}
+		select {
+		case sub.c <- event:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Close ends every subscription and refuses new ones
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package pubsub
+
+import "testing"
+
+// receive returns the events buffered for sub and whether its channel is still open
+func receive(sub *Subscription) ([]Event, bool) {
+	var events []Event
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				return events, false
+			}
+			events = append(events, event)
+		default:
+			return events, true
+		}
+	}
+}
+
+func TestPublishFiltersByUserAndLink(t *testing.T) {
+	hub := NewHub(4)
+	all := hub.Subscribe(1, 0)
+	one := hub.Subscribe(1, 7)
+	other := hub.Subscribe(2, 0)
+
+	hub.Publish(1, Event{Type: "link.clicked", LinkID: 7})
+	hub.Publish(1, Event{Type: "link.clicked", LinkID: 8})
+
+	if events, _ := receive(all); len(events) != 2 {
+		t.Errorf("subscriber to all links got %d events, want 2", len(events))
+	}
+	if events, _ := receive(one); len(events) != 1 || events[0].LinkID != 7 {
+		t.Errorf("subscriber to link 7 got %+v, want only the event about link 7", events)
+	}
+	if events, _ := receive(other); len(events) != 0 {
+		t.Errorf("another user's subscriber got %+v", events)
+	}
+}
+
+func TestPublishDropsSubscriberWithFullBuffer(t *testing.T) {
+	hub := NewHub(2)
+	slow := hub.Subscribe(1, 0)
+	fast := hub.Subscribe(1, 0)
+
+	hub.Publish(1, Event{Type: "a"})
+	hub.Publish(1, Event{Type: "b"})
+	if events, open := receive(fast); len(events) != 2 || !open {
+		t.Fatalf("fast subscriber got %d events, open %v; want 2 and open", len(events), open)
+	}
+
+	// The slow subscriber hasn't read anything, so the third event overflows it
+	hub.Publish(1, Event{Type: "c"})
+	events, open := receive(slow)
+	if open {
+		t.Fatal("subscriber with a full buffer is still subscribed")
+	}
+	if len(events) != 2 || events[0].Type != "a" || events[1].Type != "b" {
+		t.Errorf("dropped subscriber got %+v, want the two buffered events", events)
+	}
+
+	// Others keep receiving
+	if events, open := receive(fast); len(events) != 1 || !open {
+		t.Errorf("fast subscriber got %d events, open %v; want 1 and open", len(events), open)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	hub := NewHub(4)
+	sub := hub.Subscribe(1, 0)
+
+	hub.Unsubscribe(sub)
+	hub.Unsubscribe(sub)
+	hub.Publish(1, Event{Type: "link.created"})
+
+	if events, open := receive(sub); open || len(events) != 0 {
+		t.Errorf("unsubscribed subscription got %+v, open %v", events, open)
+	}
+	if len(hub.subs) != 0 {
+		t.Errorf("hub still tracks %d users", len(hub.subs))
+	}
+}
+
+func TestCloseEndsSubscriptions(t *testing.T) {
+	hub := NewHub(4)
+	subs := []*Subscription{hub.Subscribe(1, 0), hub.Subscribe(1, 3), hub.Subscribe(2, 0)}
+
+	hub.Close()
+	for i, sub := range subs {
+		if _, open := receive(sub); open {
+			t.Errorf("subscription %d is still open after Close", i)
+		}
+	}
+	if sub := hub.Subscribe(1, 0); sub != nil {
+		t.Error("Subscribe() after Close returned a subscription")
+	}
+
+	// Unsubscribing a subscription the hub already ended is harmless
+	hub.Unsubscribe(subs[0])
+}
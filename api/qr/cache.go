@@ -0,0 +1,87 @@
+package qr
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache keeps rendered QR codes in memory, evicting the oldest entries once full.
+// Entries are grouped by link so they can be dropped when the link changes.
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+	byLink   map[uint]map[string]struct{}
+}
+
+type cacheEntry struct {
+	key    string
+	linkID uint
+	data   []byte
+}
+
+// NewCache returns a cache holding at most capacity rendered images
+func NewCache(capacity int) *Cache {
+	return &Cache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+		byLink:   map[uint]map[string]struct{}{},
+	}
+}
+
+// Get returns the cached image for key, if any
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).data, true
+}
+
+// Put stores the image rendered for linkID under key
+func (c *Cache) Put(linkID uint, key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, found := c.entries[key]; found {
+		element.Value.(*cacheEntry).data = data
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, linkID: linkID, data: data})
+	if c.byLink[linkID] == nil {
+		c.byLink[linkID] = map[string]struct{}{}
+	}
+	c.byLink[linkID][key] = struct{}{}
+
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// Invalidate drops every image rendered for linkID
+func (c *Cache) Invalidate(linkID uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.byLink[linkID] {
+		c.remove(c.entries[key])
+	}
+}
+
+func (c *Cache) remove(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	delete(c.byLink[entry.linkID], entry.key)
+	if len(c.byLink[entry.linkID]) == 0 {
+		delete(c.byLink, entry.linkID)
+	}
+}
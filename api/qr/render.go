@@ -0,0 +1,225 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	MinSize   = 64
+	MaxSize   = 2048
+	MaxMargin = 16
+
+	// logoScale is the share of the symbol width covered by a centered logo.
+	// High error correction recovers up to 30% damage, so this stays well below.
+	logoScale = 0.2
+)
+
+// Options controls how a QR code is rendered
+type Options struct {
+	Size       int
+	Level      string
+	Margin     int
+	Foreground color.RGBA
+	Background color.RGBA
+	Logo       image.Image
+}
+
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// ParseLevel validates an error-correction level (L, M, Q or H)
+func ParseLevel(level string) (string, error) {
+	level = strings.ToUpper(level)
+	if _, ok := levels[level]; !ok {
+		return "", errors.New("level must be one of L, M, Q or H")
+	}
+	return level, nil
+}
+
+// ParseColor parses a hex color such as "#1a2b3c", "1a2b3c" or "fff"
+func ParseColor(hex string) (color.RGBA, error) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", hex)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", hex)
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff}, nil
+}
+
+// HexColor formats c as a CSS hex color
+func HexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// bitmap encodes content and returns its modules without the quiet zone
+func bitmap(content string, opts Options) ([][]bool, error) {
+	level := opts.Level
+	// A logo hides part of the symbol, so make sure there's enough redundancy
+	if opts.Logo != nil && level != "H" {
+		level = "Q"
+	}
+
+	code, err := qrcode.New(content, levels[level])
+	if err != nil {
+		return nil, err
+	}
+	code.DisableBorder = true
+	return code.Bitmap(), nil
+}
+
+// layout returns the module size in pixels and the offset of the symbol so that
+// it is centered in an opts.Size square with at least opts.Margin modules around it
+func layout(modules int, opts Options) (int, int) {
+	total := modules + 2*opts.Margin
+	moduleSize := opts.Size / total
+	if moduleSize < 1 {
+		moduleSize = 1
+	}
+	offset := (opts.Size - moduleSize*modules) / 2
+	return moduleSize, offset
+}
+
+// logoRect returns where the logo is drawn, keeping its aspect ratio
+func logoRect(logo image.Image, symbolOffset, symbolSize int) image.Rectangle {
+	bounds := logo.Bounds()
+	maxSide := int(float64(symbolSize) * logoScale)
+	width, height := maxSide, maxSide
+	if bounds.Dx() > bounds.Dy() {
+		height = maxSide * bounds.Dy() / bounds.Dx()
+	} else if bounds.Dy() > bounds.Dx() {
+		width = maxSide * bounds.Dx() / bounds.Dy()
+	}
+	center := symbolOffset + symbolSize/2
+	return image.Rect(center-width/2, center-height/2, center-width/2+width, center-height/2+height)
+}
+
+// drawScaled draws src over dst scaled to fit rect, using nearest-neighbour sampling
+func drawScaled(dst draw.Image, rect image.Rectangle, src image.Image) {
+	bounds := src.Bounds()
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		sy := bounds.Min.Y + (y-rect.Min.Y)*bounds.Dy()/rect.Dy()
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			sx := bounds.Min.X + (x-rect.Min.X)*bounds.Dx()/rect.Dx()
+			pixel := image.Rect(x, y, x+1, y+1)
+			draw.Draw(dst, pixel, &image.Uniform{src.At(sx, sy)}, image.Point{}, draw.Over)
+		}
+	}
+}
+
+// RenderPNG renders content as a PNG QR code
+func RenderPNG(content string, opts Options) ([]byte, error) {
+	modules, err := bitmap(content, opts)
+	if err != nil {
+		return nil, err
+	}
+	moduleSize, offset := layout(len(modules), opts)
+
+	img := image.NewRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{opts.Background}, image.Point{}, draw.Src)
+	foreground := &image.Uniform{opts.Foreground}
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			px, py := offset+x*moduleSize, offset+y*moduleSize
+			draw.Draw(img, image.Rect(px, py, px+moduleSize, py+moduleSize), foreground, image.Point{}, draw.Src)
+		}
+	}
+
+	if opts.Logo != nil {
+		rect := logoRect(opts.Logo, offset, moduleSize*len(modules))
+		// Clear the area under the logo so it stays readable
+		pad := moduleSize
+		draw.Draw(img, rect.Inset(-pad), &image.Uniform{opts.Background}, image.Point{}, draw.Src)
+		drawScaled(img, rect, opts.Logo)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderSVG renders content as an SVG QR code
+func RenderSVG(content string, opts Options) ([]byte, error) {
+	modules, err := bitmap(content, opts)
+	if err != nil {
+		return nil, err
+	}
+	moduleSize, offset := layout(len(modules), opts)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, opts.Size, opts.Size)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`, HexColor(opts.Background))
+
+	// Draw horizontal runs of dark modules as a single path
+	fmt.Fprintf(&buf, `<path fill="%s" d="`, HexColor(opts.Foreground))
+	for y, row := range modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv%dh-%dz", offset+start*moduleSize, offset+y*moduleSize,
+				(x-start)*moduleSize, moduleSize, (x-start)*moduleSize)
+		}
+	}
+	buf.WriteString(`"/>`)
+
+	if opts.Logo != nil {
+		rect := logoRect(opts.Logo, offset, moduleSize*len(modules))
+		var logoPNG bytes.Buffer
+		if err := png.Encode(&logoPNG, opts.Logo); err != nil {
+			return nil, err
+		}
+		padded := rect.Inset(-moduleSize)
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`,
+			padded.Min.X, padded.Min.Y, padded.Dx(), padded.Dy(), HexColor(opts.Background))
+		fmt.Fprintf(&buf, `<image x="%d" y="%d" width="%d" height="%d" href="data:image/png;base64,%s"/>`,
+			rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), base64.StdEncoding.EncodeToString(logoPNG.Bytes()))
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+// LoadLogo decodes a PNG or JPEG logo file
+func LoadLogo(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	logo, _, err := image.Decode(file)
+	return logo, err
+}
@@ -0,0 +1,76 @@
+package shortcode
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync/atomic"
+)
+
+const (
+	// Base62 is digits and ASCII letters, safe in URLs without escaping
+	Base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// Readable is lowercase only, since codes are unique ignoring case, and leaves
+	// out characters that are easily confused: 0/o, 1/i/l
+	Readable = "23456789abcdefghjkmnpqrstuvwxyz"
+
+	// collisionsPerLength is how many collisions in a row make a generator grow its codes
+	collisionsPerLength = 3
+)
+
+// CodeGenerator proposes short codes. attempt is the number of candidates already
+// rejected as taken for the same link; generators use it to grow their codes as
+// the keyspace fills up. Pass 0 to replace a candidate rejected for another
+// reason, which proposes a code of the current length without counting a collision.
+type CodeGenerator interface {
+	Generate(attempt int) (string, error)
+}
+
+// growingLength is a code length that grows by one whenever a link needed
+// collisionsPerLength more attempts. The growth is kept for later links, since
+// a crowded keyspace doesn't get emptier.
+type growingLength struct {
+	current atomic.Int64
+}
+
+func newGrowingLength(min int) *growingLength {
+	length := &growingLength{}
+	length.current.Store(int64(min))
+	return length
+}
+
+func (l *growingLength) forAttempt(attempt int) int {
+	current := l.current.Load()
+	if attempt > 0 && attempt%collisionsPerLength == 0 {
+		l.current.CompareAndSwap(current, current+1)
+		current = l.current.Load()
+	}
+	return int(current)
+}
+
+// RandomGenerator picks characters of an alphabet uniformly at random
+type RandomGenerator struct {
+	alphabet string
+	length   *growingLength
+}
+
+// NewRandomGenerator returns a generator of random codes of at least minLength characters
+func NewRandomGenerator(alphabet string, minLength int) *RandomGenerator {
+	return &RandomGenerator{alphabet: alphabet, length: newGrowingLength(minLength)}
+}
+
+func (g *RandomGenerator) Generate(attempt int) (string, error) {
+	return randomString(g.alphabet, g.length.forAttempt(attempt))
+}
+
+func randomString(alphabet string, length int) (string, error) {
+	code := make([]byte, length)
+	max := big.NewInt(int64(len(alphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
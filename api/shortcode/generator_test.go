@@ -0,0 +1,60 @@
+package shortcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGrowingLength(t *testing.T) {
+	length := newGrowingLength(4)
+
+	steps := []struct {
+		attempt int
+		want    int
+	}{
+		{0, 4},
+		{1, 4},
+		{2, 4},
+		// The third collision in a row grows codes by one
+		{3, 5},
+		// and later links keep the longer codes
+		{0, 5},
+		{1, 5},
+		{3, 6},
+		{6, 7},
+	}
+	for _, step := range steps {
+		if got := length.forAttempt(step.attempt); got != step.want {
+			t.Fatalf("forAttempt(%d) = %d, want %d", step.attempt, got, step.want)
+		}
+	}
+}
+
+func TestRandomGeneratorUsesAlphabet(t *testing.T) {
+	g := NewRandomGenerator(Readable, 6)
+	for i := 0; i < 100; i++ {
+		code, err := g.Generate(0)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if len(code) != 6 {
+			t.Fatalf("Generate() = %q, want 6 characters", code)
+		}
+		for _, c := range code {
+			if !strings.ContainsRune(Readable, c) {
+				t.Fatalf("Generate() = %q, which has %q outside the alphabet", code, c)
+			}
+		}
+	}
+}
+
+func TestRandomGeneratorGrowsAfterCollisions(t *testing.T) {
+	g := NewRandomGenerator(Base62, 5)
+	code, err := g.Generate(collisionsPerLength)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(code) != 6 {
+		t.Errorf("Generate(%d) = %q, want 6 characters", collisionsPerLength, code)
+	}
+}
@@ -0,0 +1,80 @@
+package shortcode
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// sequenceMultiplier scrambles sequence numbers. It must share no factor with the
+// alphabet size so that multiplying by it is a permutation of each length's keyspace.
+const sequenceMultiplier = 9_576_890_767
+
+// SequenceGenerator turns numbers from an increasing sequence into codes, like
+// hashids: consecutive numbers give unrelated-looking codes, and different numbers
+// give different codes. With a single-case alphabet such as Readable they also
+// differ ignoring case, so they only collide with custom codes, which just skip
+// a number.
+type SequenceGenerator struct {
+	next      func() (uint64, error)
+	alphabet  string
+	offset    uint64
+	minLength int
+}
+
+// NewSequenceGenerator returns a generator encoding numbers from next. The alphabet
+// is shuffled with salt, so different salts produce different codes.
+func NewSequenceGenerator(next func() (uint64, error), alphabet, salt string, minLength int) *SequenceGenerator {
+	seed := sha256.Sum256([]byte(salt))
+	shuffled := []byte(alphabet)
+	rng := rand.New(rand.NewPCG(binary.BigEndian.Uint64(seed[:8]), binary.BigEndian.Uint64(seed[8:16])))
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return &SequenceGenerator{
+		next:      next,
+		alphabet:  string(shuffled),
+		offset:    binary.BigEndian.Uint64(seed[16:24]),
+		minLength: minLength,
+	}
+}
+
+func (g *SequenceGenerator) Generate(int) (string, error) {
+	n, err := g.next()
+	if err != nil {
+		return "", err
+	}
+	return g.Encode(n), nil
+}
+
+// Encode maps n to a code of the shortest length (at least minLength) whose
+// keyspace holds n, scrambled within that keyspace by multiplying it and adding
+// a salt-derived offset, both of which are permutations
+func (g *SequenceGenerator) Encode(n uint64) string {
+	base := uint64(len(g.alphabet))
+	length, keyspace := g.minLength, pow(base, g.minLength)
+	for n >= keyspace {
+		length++
+		keyspace *= base
+	}
+
+	hi, lo := bits.Mul64(n, sequenceMultiplier%keyspace)
+	_, scrambled := bits.Div64(hi%keyspace, lo, keyspace)
+	scrambled = (scrambled + g.offset%keyspace) % keyspace
+
+	code := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		code[i] = g.alphabet[scrambled%base]
+		scrambled /= base
+	}
+	return string(code)
+}
+
+func pow(base uint64, exp int) uint64 {
+	result := uint64(1)
+	for i := 0; i < exp; i++ {
+		result *= base
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package shortcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSequenceEncodeIsPermutation(t *testing.T) {
+	g := NewSequenceGenerator(nil, Readable, "salt", 2)
+	keyspace := uint64(len(Readable) * len(Readable))
+
+	seen := make(map[string]uint64, keyspace)
+	for n := uint64(0); n < keyspace; n++ {
+		code := g.Encode(n)
+		if len(code) != 2 {
+			t.Fatalf("Encode(%d) = %q, want 2 characters", n, code)
+		}
+		if strings.ToLower(code) != code {
+			t.Fatalf("Encode(%d) = %q, want a lowercase code", n, code)
+		}
+		if other, ok := seen[code]; ok {
+			t.Fatalf("Encode(%d) and Encode(%d) both give %q", other, n, code)
+		}
+		seen[code] = n
+	}
+
+	// The next number no longer fits in two characters
+	if code := g.Encode(keyspace); len(code) != 3 {
+		t.Errorf("Encode(%d) = %q, want 3 characters", keyspace, code)
+	}
+}
+
+func TestSequenceEncodeDependsOnSalt(t *testing.T) {
+	a := NewSequenceGenerator(nil, Readable, "one", 4)
+	b := NewSequenceGenerator(nil, Readable, "two", 4)
+	for n := uint64(0); n < 5; n++ {
+		if a.Encode(n) != b.Encode(n) {
+			return
+		}
+	}
+	t.Error("different salts gave the same codes")
+}
+
+func TestSequenceGenerateUsesNextNumber(t *testing.T) {
+	var n uint64
+	g := NewSequenceGenerator(func() (uint64, error) {
+		n++
+		return n, nil
+	}, Readable, "salt", 4)
+
+	for i := uint64(1); i <= 3; i++ {
+		code, err := g.Generate(0)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if want := g.Encode(i); code != want {
+			t.Errorf("Generate() #%d = %q, want Encode(%d) = %q", i, code, i, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package shortcode
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ReservedWords can't be claimed as custom codes: they are routes of the API or
+// the dashboard, or could be mistaken for official pages
+var ReservedWords = []string{
+	// Routes
+	"api", "r", "health", "favicons", "favicon.ico", "robots.txt", "auth", "dashboard", "profile",
+	"login", "logout", "signup", "register", "settings", "static", "assets", "_next",
+	// Brand and official-looking terms
+	"blinky", "admin", "administrator", "root", "support", "help", "security", "billing",
+	"account", "accounts", "official", "status", "www", "mail", "abuse", "report",
+}
+
+var codeCharset = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidationError says which rule a custom code broke
+type ValidationError struct {
+	Rule    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Validator checks custom codes chosen by users
+type Validator struct {
+	MinLength int
+	MaxLength int
+	// Reserved codes are refused outright, ignoring case
+	Reserved map[string]bool
+	// Blocked words, e.g. profanity, are refused anywhere in a code, ignoring case
+	Blocked []string
+}
+
+// NewValidator returns a validator reserving ReservedWords
+func NewValidator(minLength, maxLength int) *Validator {
+	reserved := map[string]bool{}
+	for _, word := range ReservedWords {
+		reserved[word] = true
+	}
+	return &Validator{MinLength: minLength, MaxLength: maxLength, Reserved: reserved}
+}
+
+// LoadBlockedWords adds the words in a file, one per line, to the blocked words.
+// Blank lines and # comments are ignored.
+func (v *Validator) LoadBlockedWords(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if word := strings.ToLower(strings.TrimSpace(line)); word != "" {
+			v.Blocked = append(v.Blocked, word)
+		}
+	}
+	return scanner.Err()
+}
+
+// Validate returns a *ValidationError if code may not be used as a custom code
+func (v *Validator) Validate(code string) error {
+	length := len(code)
+	switch {
+	case length < v.MinLength:
+		return &ValidationError{"min_length", fmt.Sprintf("Custom code must be at least %d characters long", v.MinLength)}
+	case length > v.MaxLength:
+		return &ValidationError{"max_length", fmt.Sprintf("Custom code must be at most %d characters long", v.MaxLength)}
+	case !codeCharset.MatchString(code):
+		return &ValidationError{"charset", "Custom code may only contain letters, digits, '-' and '_'"}
+	}
+	return v.CheckWords(code)
+}
+
+// CheckWords returns a *ValidationError if code is reserved or contains a blocked
+// word. Generated codes are only held to these rules.
+func (v *Validator) CheckWords(code string) error {
+	lower := strings.ToLower(code)
+	if v.Reserved[lower] {
+		return &ValidationError{"reserved", fmt.Sprintf("Custom code %q is reserved", code)}
+	}
+	for _, word := range v.Blocked {
+		if strings.Contains(lower, word) {
+			return &ValidationError{"blocked_word", "Custom code contains a word that isn't allowed"}
+		}
+	}
+	return nil
+}
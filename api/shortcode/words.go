@@ -0,0 +1,61 @@
+package shortcode
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// Short, unambiguous words that are easy to read aloud and type
+var (
+	adjectives = []string{
+		"able", "bold", "brave", "bright", "calm", "clean", "clever", "cool",
+		"cozy", "crisp", "daring", "eager", "early", "easy", "fair", "fancy",
+		"fast", "fine", "fresh", "funny", "gentle", "giant", "glad", "golden",
+		"good", "grand", "green", "happy", "honest", "jolly", "kind", "lively",
+		"lucky", "merry", "mighty", "modern", "neat", "noble", "proud", "quick",
+		"quiet", "rapid", "ready", "red", "rich", "royal", "shiny", "silent",
+		"simple", "smart", "smooth", "snowy", "solid", "sunny", "super", "sweet",
+		"swift", "tidy", "tiny", "vivid", "warm", "wild", "wise", "young",
+	}
+	nouns = []string{
+		"apple", "arrow", "badger", "beach", "bear", "bird", "breeze", "brook",
+		"cactus", "canyon", "cedar", "cloud", "comet", "coral", "crane", "creek",
+		"daisy", "dolphin", "dragon", "eagle", "falcon", "fern", "field", "flame",
+		"forest", "fox", "garden", "glacier", "harbor", "hawk", "island", "jungle",
+		"koala", "lake", "lemon", "lion", "lotus", "maple", "meadow", "moon",
+		"mountain", "ocean", "orchid", "otter", "owl", "panda", "pebble", "pine",
+		"planet", "pony", "rabbit", "river", "robin", "rocket", "sparrow", "star",
+		"stone", "storm", "summit", "tiger", "tulip", "valley", "whale", "wolf",
+	}
+)
+
+// WordsGenerator builds codes from random words, e.g. "sunny-otter". Codes
+// start with minWords words and get one more adjective as the keyspace fills.
+type WordsGenerator struct {
+	words *growingLength
+}
+
+func NewWordsGenerator(minWords int) *WordsGenerator {
+	if minWords < 2 {
+		minWords = 2
+	}
+	return &WordsGenerator{words: newGrowingLength(minWords)}
+}
+
+func (g *WordsGenerator) Generate(attempt int) (string, error) {
+	count := g.words.forAttempt(attempt)
+	words := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		list := adjectives
+		if i == count-1 {
+			list = nouns
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(list))))
+		if err != nil {
+			return "", err
+		}
+		words = append(words, list[n.Int64()])
+	}
+	return strings.Join(words, "-"), nil
+}
@@ -0,0 +1,115 @@
+package urlpolicy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// HashList holds SHA-256 hash prefixes of unsafe URL expressions, in the style of
+// Google Safe Browsing lists. Prefixes can be 4 to 32 bytes long.
+type HashList struct {
+	// prefixes maps a prefix length to the set of prefixes of that length
+	prefixes map[int]map[string]bool
+}
+
+// LoadHashList reads one hex-encoded hash prefix per line, ignoring blank lines and # comments
+func LoadHashList(path string) (*HashList, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &HashList{prefixes: map[int]map[string]bool{}}
+	for i, line := range lines {
+		prefix, err := hex.DecodeString(line)
+		if err != nil || len(prefix) < 4 || len(prefix) > sha256.Size {
+			return nil, fmt.Errorf("%s: invalid hash prefix %q (entry %d)", path, line, i+1)
+		}
+		if list.prefixes[len(prefix)] == nil {
+			list.prefixes[len(prefix)] = map[string]bool{}
+		}
+		list.prefixes[len(prefix)][string(prefix)] = true
+	}
+	return list, nil
+}
+
+// Len returns the number of prefixes in the list
+func (l *HashList) Len() int {
+	total := 0
+	if l != nil {
+		for _, set := range l.prefixes {
+			total += len(set)
+		}
+	}
+	return total
+}
+
+// Matches reports whether any lookup expression of target hashes to a listed prefix
+func (l *HashList) Matches(target *url.URL) bool {
+	if l.Len() == 0 {
+		return false
+	}
+	for _, expression := range Expressions(target) {
+		sum := sha256.Sum256([]byte(expression))
+		for length, set := range l.prefixes {
+			if set[string(sum[:length])] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Expressions returns the host-suffix/path-prefix combinations a URL is looked up
+// by, e.g. a.b.example.com/1/2.html?x yields b.example.com/1/ among others.
+// Hashing these is how entries in the list are produced.
+func Expressions(target *url.URL) []string {
+	host := normalizeHost(target.Hostname())
+	if host == "" {
+		return nil
+	}
+
+	// The exact host plus up to four suffixes built from the last five components
+	hosts := []string{host}
+	if net.ParseIP(host) == nil {
+		components := strings.Split(host, ".")
+		start := len(components) - 5
+		if start < 1 {
+			start = 1
+		}
+		for i := start; i < len(components)-1; i++ {
+			hosts = append(hosts, strings.Join(components[i:], "."))
+		}
+	}
+
+	// The exact path with and without query, plus up to four leading directories
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	var paths []string
+	if target.RawQuery != "" {
+		paths = append(paths, path+"?"+target.RawQuery)
+	}
+	paths = append(paths, path)
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	prefix := "/"
+	for i := 0; i < len(segments) && i < 4; i++ {
+		if prefix != path {
+			paths = append(paths, prefix)
+		}
+		prefix += segments[i] + "/"
+	}
+
+	expressions := make([]string, 0, len(hosts)*len(paths))
+	for _, h := range hosts {
+		for _, p := range paths {
+			expressions = append(expressions, h+p)
+		}
+	}
+	return expressions
+}
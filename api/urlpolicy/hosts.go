@@ -0,0 +1,77 @@
+package urlpolicy
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// HostList is a set of hostnames. An entry also covers all of its subdomains.
+type HostList map[string]bool
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// NewHostList builds a list from hostnames
+func NewHostList(hosts ...string) HostList {
+	list := HostList{}
+	for _, host := range hosts {
+		if host = normalizeHost(host); host != "" {
+			list[host] = true
+		}
+	}
+	return list
+}
+
+// LoadHostList reads one hostname per line, ignoring blank lines and # comments
+func LoadHostList(path string) (HostList, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewHostList(lines...), nil
+}
+
+// Contains reports whether host or one of its parent domains is in the list
+func (l HostList) Contains(host string) bool {
+	host = normalizeHost(host)
+	for host != "" {
+		if l[host] {
+			return true
+		}
+		dot := strings.IndexByte(host, '.')
+		if dot < 0 {
+			break
+		}
+		host = host[dot+1:]
+	}
+	return false
+}
+
+// ContainsExact reports whether host itself is in the list
+func (l HostList) ContainsExact(host string) bool {
+	return l[normalizeHost(host)]
+}
+
+// readLines returns the meaningful lines of a list file
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,49 @@
+package urlpolicy
+
+import "fmt"
+
+// Config points at the list files of a policy. Empty paths disable that list.
+type Config struct {
+	AllowlistPath string
+	BlocklistPath string
+	HashListPath  string
+	// OwnHosts are the hosts serving our short links
+	OwnHosts      []string
+	IsShortDomain func(host string) bool
+}
+
+// Load reads the configured lists and assembles the pipeline
+func Load(cfg Config) (*Policy, error) {
+	policy := &Policy{
+		Schemes: SchemeRule{Allowed: []string{"http", "https"}},
+		Rules: []Rule{
+			OwnDomainRule{Hosts: NewHostList(cfg.OwnHosts...), IsShortDomain: cfg.IsShortDomain},
+		},
+	}
+
+	if cfg.AllowlistPath != "" {
+		allowlist, err := LoadHostList(cfg.AllowlistPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading allowlist: %w", err)
+		}
+		policy.Allowlist = allowlist
+	}
+
+	if cfg.BlocklistPath != "" {
+		blocklist, err := LoadHostList(cfg.BlocklistPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading blocklist: %w", err)
+		}
+		policy.Rules = append(policy.Rules, BlocklistRule{Hosts: blocklist})
+	}
+
+	if cfg.HashListPath != "" {
+		hashList, err := LoadHashList(cfg.HashListPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading hash list: %w", err)
+		}
+		policy.Rules = append(policy.Rules, HashListRule{List: hashList})
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,132 @@
+package urlpolicy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Rejection explains why a URL may not be shortened
+type Rejection struct {
+	Rule   string
+	Reason string
+}
+
+func (r *Rejection) Error() string {
+	return r.Reason
+}
+
+// Rule is one check of the pipeline. It returns nil when the URL passes.
+type Rule interface {
+	Name() string
+	Check(target *url.URL) *Rejection
+}
+
+// Policy runs URLs through its rules in order and stops at the first rejection
+type Policy struct {
+	// Trusted hosts skip every rule but the scheme check
+	Allowlist HostList
+	Rules     []Rule
+	Schemes   SchemeRule
+}
+
+// Check parses raw and runs it through the pipeline
+func (p *Policy) Check(raw string) *Rejection {
+	target, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return &Rejection{Rule: "syntax", Reason: "URL could not be parsed"}
+	}
+
+	if rejection := p.Schemes.Check(target); rejection != nil {
+		return rejection
+	}
+	if target.Hostname() == "" {
+		return &Rejection{Rule: "syntax", Reason: "URL has no host"}
+	}
+	if p.Allowlist.Contains(target.Hostname()) {
+		return nil
+	}
+
+	for _, rule := range p.Rules {
+		if rejection := rule.Check(target); rejection != nil {
+			if rejection.Rule == "" {
+				rejection.Rule = rule.Name()
+			}
+			return rejection
+		}
+	}
+	return nil
+}
+
+// SchemeRule only lets through URLs with an allowed scheme
+type SchemeRule struct {
+	Allowed []string
+}
+
+func (r SchemeRule) Name() string {
+	return "scheme"
+}
+
+func (r SchemeRule) Check(target *url.URL) *Rejection {
+	scheme := strings.ToLower(target.Scheme)
+	for _, allowed := range r.Allowed {
+		if scheme == allowed {
+			return nil
+		}
+	}
+	if scheme == "" {
+		return &Rejection{Rule: r.Name(), Reason: "URL has no scheme"}
+	}
+	return &Rejection{Rule: r.Name(), Reason: fmt.Sprintf("URL scheme %q is not allowed", scheme)}
+}
+
+// BlocklistRule rejects hosts on a local blocklist
+type BlocklistRule struct {
+	Hosts HostList
+}
+
+func (r BlocklistRule) Name() string {
+	return "blocklist"
+}
+
+func (r BlocklistRule) Check(target *url.URL) *Rejection {
+	if r.Hosts.Contains(target.Hostname()) {
+		return &Rejection{Rule: r.Name(), Reason: fmt.Sprintf("Domain %s is blocked", normalizeHost(target.Hostname()))}
+	}
+	return nil
+}
+
+// OwnDomainRule refuses to shorten our own short links, which would redirect in a loop
+type OwnDomainRule struct {
+	Hosts HostList
+	// IsShortDomain reports whether host is a custom short domain
+	IsShortDomain func(host string) bool
+}
+
+func (r OwnDomainRule) Name() string {
+	return "own_domain"
+}
+
+func (r OwnDomainRule) Check(target *url.URL) *Rejection {
+	host := normalizeHost(target.Hostname())
+	if r.Hosts.ContainsExact(host) || (r.IsShortDomain != nil && r.IsShortDomain(host)) {
+		return &Rejection{Rule: r.Name(), Reason: "URL is already a short link"}
+	}
+	return nil
+}
+
+// HashListRule rejects URLs matching a list of known unsafe URL hashes
+type HashListRule struct {
+	List *HashList
+}
+
+func (r HashListRule) Name() string {
+	return "unsafe_list"
+}
+
+func (r HashListRule) Check(target *url.URL) *Rejection {
+	if r.List.Matches(target) {
+		return &Rejection{Rule: r.Name(), Reason: "URL is on a list of known phishing or malware sites"}
+	}
+	return nil
+}
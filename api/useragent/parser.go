@@ -0,0 +1,171 @@
+package useragent
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Kinds of definitions
+const (
+	KindBrowser = "browser"
+	KindOS      = "os"
+	KindDevice  = "device"
+)
+
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+//go:embed definitions.txt
+var defaultDefinitions string
+
+// Agent is what a User-Agent header says about the visitor. Names are lowercase,
+// e.g. "chrome", "ios", "mobile"; unknown values are empty.
+type Agent struct {
+	Browser        string
+	BrowserVersion string
+	OS             string
+	OSVersion      string
+	Device         string
+}
+
+// definition maps a User-Agent regexp to a name. Its first capture group, if
+// any, is the version.
+type definition struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// Parser turns User-Agent headers into Agents using a list of definitions
+type Parser struct {
+	browsers         []definition
+	operatingSystems []definition
+	devices          []definition
+}
+
+// ParseDefinitions reads definitions, one per line: a kind (browser, os or
+// device), a name and a regular expression. Blank lines and lines starting
+// with # are ignored.
+func ParseDefinitions(r io.Reader) (*Parser, error) {
+	p := &Parser{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kind, rest, _ := strings.Cut(line, " ")
+		name, expr, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		expr = strings.TrimSpace(expr)
+		if name == "" || expr == "" {
+			return nil, fmt.Errorf("line %d: expected a kind, a name and a pattern", n)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		def := definition{name: strings.ToLower(name), re: re}
+		switch kind {
+		case KindBrowser:
+			p.browsers = append(p.browsers, def)
+		case KindOS:
+			p.operatingSystems = append(p.operatingSystems, def)
+		case KindDevice:
+			p.devices = append(p.devices, def)
+		default:
+			return nil, fmt.Errorf("line %d: unknown kind %q", n, kind)
+		}
+	}
+	return p, scanner.Err()
+}
+
+// NewParser returns a Parser using the built-in definitions
+func NewParser() *Parser {
+	p, err := ParseDefinitions(strings.NewReader(defaultDefinitions))
+	if err != nil {
+		panic("useragent: invalid built-in definitions: " + err.Error())
+	}
+	return p
+}
+
+// LoadParser returns a Parser using the definitions in the file at path
+func LoadParser(path string) (*Parser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	p, err := ParseDefinitions(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse extracts the browser, OS and device class from a User-Agent header
+func (p *Parser) Parse(userAgent string) Agent {
+	if strings.TrimSpace(userAgent) == "" {
+		return Agent{}
+	}
+
+	agent := Agent{Device: DeviceDesktop}
+	agent.Browser, agent.BrowserVersion = match(p.browsers, userAgent)
+	agent.OS, agent.OSVersion = match(p.operatingSystems, userAgent)
+	if device, _ := match(p.devices, userAgent); device != "" {
+		agent.Device = device
+	}
+	return agent
+}
+
+// Names lists the names Parse can report for a kind, e.g. every browser
+func (p *Parser) Names(kind string) []string {
+	var defs []definition
+	var names []string
+	switch kind {
+	case KindBrowser:
+		defs = p.browsers
+	case KindOS:
+		defs = p.operatingSystems
+	case KindDevice:
+		defs = p.devices
+		names = append(names, DeviceDesktop)
+	}
+
+	seen := map[string]bool{}
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, def := range defs {
+		if !seen[def.name] {
+			seen[def.name] = true
+			names = append(names, def.name)
+		}
+	}
+	return names
+}
+
+func match(defs []definition, userAgent string) (string, string) {
+	for _, def := range defs {
+		groups := def.re.FindStringSubmatch(userAgent)
+		if groups == nil {
+			continue
+		}
+		version := ""
+		if len(groups) > 1 {
+			version = strings.ReplaceAll(groups[1], "_", ".")
+		}
+		return def.name, version
+	}
+	return "", ""
+}
@@ -0,0 +1,127 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/caiohportella/blinky/models"
+	"github.com/caiohportella/blinky/safehttp"
+	"gorm.io/gorm"
+)
+
+const (
+	userAgent = "BlinkyWebhooks/1.0"
+
+	// maxResponseBody bounds how much of a receiver's response is kept in the delivery log
+	maxResponseBody = 2 << 10
+)
+
+// Dispatcher sends deliveries and records their outcome
+type Dispatcher struct {
+	Client      *http.Client
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry; it doubles with each further one
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+}
+
+// NewDispatcher returns a Dispatcher that doesn't follow redirects and refuses
+// internal addresses unless allowPrivate is set
+func NewDispatcher(timeout time.Duration, maxAttempts int, retryDelay time.Duration, allowPrivate bool) *Dispatcher {
+	return &Dispatcher{
+		Client:        safehttp.NewClient(safehttp.Options{Timeout: timeout, AllowPrivate: allowPrivate}),
+		MaxAttempts:   maxAttempts,
+		RetryDelay:    retryDelay,
+		MaxRetryDelay: 12 * time.Hour,
+	}
+}
+
+// backoff is the wait before the attempt following the nth failed one
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.RetryDelay
+	for i := 1; i < attempts && delay < d.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxRetryDelay {
+		return d.MaxRetryDelay
+	}
+	return delay
+}
+
+// Send POSTs delivery to webhook and saves the outcome, scheduling a retry if it
+// failed and attempts remain. Only errors saving the outcome are returned.
+func (d *Dispatcher) Send(ctx context.Context, db *gorm.DB, webhook models.Webhook, delivery *models.WebhookDelivery) error {
+	start := time.Now()
+	status, body, sendErr := d.post(ctx, webhook, *delivery)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.DurationMs = int(now.Sub(start).Milliseconds())
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+	delivery.Error = ""
+	if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	} else if status < 200 || status > 299 {
+		delivery.Error = "Unexpected response status " + strconv.Itoa(status)
+	}
+
+	switch {
+	case delivery.Error == "":
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case delivery.Attempts >= d.MaxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(d.backoff(delivery.Attempts))
+		delivery.Status = models.WebhookDeliveryPending
+		delivery.NextAttemptAt = &next
+	}
+
+	return db.Model(delivery).Updates(map[string]interface{}{
+		"attempts":        delivery.Attempts,
+		"last_attempt_at": delivery.LastAttemptAt,
+		"duration_ms":     delivery.DurationMs,
+		"response_status": delivery.ResponseStatus,
+		"response_body":   delivery.ResponseBody,
+		"error":           delivery.Error,
+		"status":          delivery.Status,
+		"delivered_at":    delivery.DeliveredAt,
+		"next_attempt_at": delivery.NextAttemptAt,
+	}).Error
+}
+
+// post sends the delivery's payload, returning the response status and the start of its body
+func (d *Dispatcher) post(ctx context.Context, webhook models.Webhook, delivery models.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Blinky-Event", delivery.Event)
+	req.Header.Set("X-Blinky-Event-Id", delivery.EventID)
+	req.Header.Set("X-Blinky-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, time.Now().Unix(), body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if !utf8.Valid(raw) {
+		raw = nil
+	}
+	return resp.StatusCode, string(raw), nil
+}
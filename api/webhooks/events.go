@@ -0,0 +1,130 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/caiohportella/blinky/models"
+	"gorm.io/gorm"
+)
+
+// Envelope is the JSON body of every delivery
+type Envelope struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// LinkData describes a link in event payloads
+type LinkData struct {
+	ID          uint       `json:"id"`
+	ShortCode   string     `json:"shortCode"`
+	DomainID    *uint      `json:"domainId,omitempty"`
+	OriginalURL string     `json:"originalUrl"`
+	Clicks      int        `json:"clicks"`
+	ActiveFrom  *time.Time `json:"activeFrom,omitempty"`
+	ActiveUntil *time.Time `json:"activeUntil,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// ClickData describes a click in link.clicked payloads
+type ClickData struct {
+	ID          uint      `json:"id"`
+	Destination string    `json:"destination"`
+	Country     string    `json:"country,omitempty"`
+	OS          string    `json:"os,omitempty"`
+	Browser     string    `json:"browser,omitempty"`
+	Device      string    `json:"device,omitempty"`
+	Referrer    string    `json:"referrer,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// NewLinkData returns the payload describing link
+func NewLinkData(link models.Link) LinkData {
+	return LinkData{
+		ID:          link.ID,
+		ShortCode:   link.ShortCode,
+		DomainID:    link.DomainID,
+		OriginalURL: link.OriginalURL,
+		Clicks:      link.Clicks,
+		ActiveFrom:  link.ActiveFrom,
+		ActiveUntil: link.ActiveUntil,
+		CreatedAt:   link.CreatedAt,
+	}
+}
+
+// NewClickData returns the payload describing click
+func NewClickData(click models.Click) ClickData {
+	return ClickData{
+		ID:          click.ID,
+		Destination: click.Destination,
+		Country:     click.Country,
+		OS:          click.OS,
+		Browser:     click.Browser,
+		Device:      click.Device,
+		Referrer:    click.Referrer,
+		CreatedAt:   click.CreatedAt,
+	}
+}
+
+// NewDelivery returns a pending delivery of a new event to webhook
+func NewDelivery(webhook models.Webhook, event string, data interface{}) (models.WebhookDelivery, error) {
+	eventID, err := randomHex(12)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	envelope := Envelope{ID: "evt_" + eventID, Event: event, CreatedAt: time.Now().UTC(), Data: data}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	now := time.Now()
+	return models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventID:       envelope.ID,
+		Event:         event,
+		Payload:       string(payload),
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+	}, nil
+}
+
+// Enqueue queues event for every active webhook of userID subscribed to it. The
+// deliveries are sent by the delivery worker.
+func Enqueue(tx *gorm.DB, userID uint, event string, data interface{}) error {
+	var hooks []models.Webhook
+	if err := tx.Where("user_id = ? AND active", userID).Find(&hooks).Error; err != nil {
+		return err
+	}
+
+	// Every webhook gets the same event, with the same ID
+	var deliveries []models.WebhookDelivery
+	for _, hook := range hooks {
+		if !hook.Subscribes(event) {
+			continue
+		}
+		if len(deliveries) == 0 {
+			delivery, err := NewDelivery(hook, event, data)
+			if err != nil {
+				return err
+			}
+			deliveries = append(deliveries, delivery)
+			continue
+		}
+		delivery := deliveries[0]
+		delivery.WebhookID = hook.ID
+		deliveries = append(deliveries, delivery)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.Omit("Webhook").Create(&deliveries).Error
+}
+
+// ClickedData is the payload of link.clicked events
+type ClickedData struct {
+	Link  LinkData  `json:"link"`
+	Click ClickData `json:"click"`
+}
@@ -30,21 +30,26 @@ function DashboardContent() {
   const router = useRouter()
   const token = useStoreValue(tokenStore)
   const [links, setLinks] = useState<Link[]>([])
+  const [nextCursor, setNextCursor] = useState<string>()
   const [loading, setLoading] = useState(true)
+  const [loadingMore, setLoadingMore] = useState(false)
   const [searchQuery, setSearchQuery] = useState('')
 
+  // Search on the server, once the user stops typing
   useEffect(() => {
-    if (token) {
-      loadLinks()
-    }
-  }, [token])
+    if (!token) return
+
+    const timeout = setTimeout(() => loadLinks(), searchQuery ? 300 : 0)
+    return () => clearTimeout(timeout)
+  }, [token, searchQuery])
 
   const loadLinks = async () => {
     if (!token) return
     
     try {
-      const data = await linksApi.getLinks(token)
-      setLinks(data)
+      const page = await linksApi.getLinks(token, { search: searchQuery })
+      setLinks(page.links)
+      setNextCursor(page.nextCursor)
     } catch (error) {
       console.error('[v0] Failed to load links:', error)
     } finally {
@@ -52,6 +57,21 @@ function DashboardContent() {
     }
   }
 
+  const loadMoreLinks = async () => {
+    if (!token || !nextCursor) return
+
+    setLoadingMore(true)
+    try {
+      const page = await linksApi.getLinks(token, { cursor: nextCursor, search: searchQuery })
+      setLinks(current => [...current, ...page.links])
+      setNextCursor(page.nextCursor)
+    } catch (error) {
+      console.error('[v0] Failed to load more links:', error)
+    } finally {
+      setLoadingMore(false)
+    }
+  }
+
   const handleDeleteLink = async (linkId: string) => {
     if (!token) return
     
@@ -59,11 +79,6 @@ function DashboardContent() {
     setLinks(links.filter(link => link.id !== linkId))
   }
 
-  const filteredLinks = links.filter(link =>
-    link.shortCode.toLowerCase().includes(searchQuery.toLowerCase()) ||
-    link.originalUrl.toLowerCase().includes(searchQuery.toLowerCase())
-  )
-
   return (
     <div className="min-h-screen bg-background relative overflow-hidden">
       {/* Background Blobs */}
@@ -141,7 +156,7 @@ function DashboardContent() {
               <div className="animate-spin w-8 h-8 border-4 border-primary border-t-transparent rounded-full mx-auto mb-4"></div>
               <p className="text-muted-foreground">{'Loading links...'}</p>
             </div>
-          ) : filteredLinks.length === 0 ? (
+          ) : links.length === 0 ? (
             <div className="text-center py-20 border-2 border-dashed border-border rounded-3xl bg-card/30">
               <p className="text-muted-foreground mb-6 text-lg">
                 {searchQuery ? 'No links match your search' : 'No links yet'}
@@ -154,13 +169,26 @@ function DashboardContent() {
             </div>
           ) : (
             <div className="space-y-4">
-              {filteredLinks.map(link => (
+              {links.map(link => (
                 <LinkItem
                   key={link.id}
                   link={link}
                   onDelete={handleDeleteLink}
                 />
               ))}
+              {nextCursor && (
+                <div className="text-center pt-4">
+                  <Button
+                    variant="outline"
+                    size="lg"
+                    className="rounded-full"
+                    disabled={loadingMore}
+                    onClick={loadMoreLinks}
+                  >
+                    {loadingMore ? 'Loading...' : 'Load more'}
+                  </Button>
+                </div>
+              )}
             </div>
           )}
         </div>
@@ -20,6 +20,12 @@ export interface Link {
   favicon?: string;
 }
 
+// LinkPage is one page of links; pass nextCursor to getLinks for the next one
+export interface LinkPage {
+  links: Link[];
+  nextCursor?: string;
+}
+
 interface ApiResponse<T> {
   success: boolean;
   data?: T;
@@ -181,9 +187,16 @@ export const authApi = {
 
 // Links API calls
 export const linksApi = {
-  async getLinks(token: string): Promise<Link[]> {
+  async getLinks(
+    token: string,
+    options: { cursor?: string; limit?: number; search?: string } = {}
+  ): Promise<LinkPage> {
     try {
-      const response = await fetch(`${API_URL}/links`, {
+      const params = new URLSearchParams({ limit: String(options.limit ?? 20) });
+      if (options.cursor) params.set("cursor", options.cursor);
+      if (options.search) params.set("search", options.search);
+
+      const response = await fetch(`${API_URL}/links?${params}`, {
         headers: { Authorization: `Bearer ${token}` },
       });
 
@@ -197,23 +210,27 @@ export const linksApi = {
           userId: number;
           favicon?: string;
         }>
-      > = await response.json();
+      > & { paging?: { hasMore: boolean; nextCursor?: string } } =
+        await response.json();
 
       if (!res.success || !res.data) {
-        return [];
+        return { links: [] };
       }
 
-      return res.data.map((link) => ({
-        id: String(link.id),
-        shortCode: link.shortCode,
-        originalUrl: link.originalUrl,
-        clicks: link.clicks,
-        createdAt: link.createdAt,
-        userId: String(link.userId),
-        favicon: link.favicon,
-      }));
+      return {
+        links: res.data.map((link) => ({
+          id: String(link.id),
+          shortCode: link.shortCode,
+          originalUrl: link.originalUrl,
+          clicks: link.clicks,
+          createdAt: link.createdAt,
+          userId: String(link.userId),
+          favicon: link.favicon,
+        })),
+        nextCursor: res.paging?.hasMore ? res.paging.nextCursor : undefined,
+      };
     } catch {
-      return [];
+      return { links: [] };
     }
   },
 
@@ -0,0 +1,71 @@
+import { NextRequest, NextResponse } from "next/server";
+
+// Routes of the app itself, which are never short codes
+const knownRoutes = ["auth", "dashboard", "profile", "api", "favicon.ico"];
+
+// Headers the API needs to see the visitor rather than this server: targeting,
+// bot detection and sticky rotation all depend on them
+const forwardedHeaders = [
+  "user-agent",
+  "accept-language",
+  "referer",
+  "cookie",
+  "purpose",
+  "sec-purpose",
+  "x-purpose",
+  "x-moz",
+  "x-forwarded-for",
+  "x-real-ip",
+];
+
+// Short links on the default domain are resolved here, before any page
+// renders, so the redirect can carry the cookies the API sets. Anything that
+// isn't a working link falls through to [shortCode]/page.tsx, which 404s.
+export async function proxy(request: NextRequest) {
+  const shortCode = request.nextUrl.pathname.slice(1);
+  if (!shortCode || knownRoutes.includes(shortCode)) {
+    return NextResponse.next();
+  }
+
+  const headers = new Headers();
+  for (const name of forwardedHeaders) {
+    const value = request.headers.get(name);
+    if (value) {
+      headers.set(name, value);
+    }
+  }
+  // Link preview fetchers often only send HEAD requests
+  headers.set("x-forwarded-method", request.method);
+
+  try {
+    const apiUrl = process.env.NEXT_PUBLIC_API_URL?.replace("/api/v1", "") || "http://localhost:8080";
+    // Forward the visitor's query so links with query passthrough can merge it
+    const response = await fetch(`${apiUrl}/r/${encodeURIComponent(shortCode)}${request.nextUrl.search}`, {
+      headers,
+      cache: "no-store", // Don't cache redirects
+    });
+    if (!response.ok) {
+      return NextResponse.next();
+    }
+
+    const data = await response.json();
+    if (!data.success || !data.data?.originalUrl) {
+      return NextResponse.next();
+    }
+
+    const redirect = NextResponse.redirect(data.data.originalUrl, 307);
+    // Relay the cookies keeping visitors on the same variant
+    for (const cookie of response.headers.getSetCookie()) {
+      redirect.headers.append("set-cookie", cookie);
+    }
+    return redirect;
+  } catch (error) {
+    console.error("Failed to fetch redirect URL:", error);
+    return NextResponse.next();
+  }
+}
+
+export const config = {
+  // Single path segments only; static files have an extension
+  matcher: ["/((?!_next/)[^/.]+)"],
+};